@@ -0,0 +1,306 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var TIME_RANGE_PRESETS = []string{"5m", "15m", "1h", "24h", "7d"}
+
+var agoPattern = regexp.MustCompile(`ago\(\s*[^)]*\)`)
+
+// swap the argument of an existing ago(...) or add a _time filter after the
+// dataset reference
+func setTimeRange(apl string, timeRange string) string {
+	ago := fmt.Sprintf("ago(%v)", timeRange)
+
+	if loc := agoPattern.FindStringIndex(apl); loc != nil {
+		return apl[:loc[0]] + ago + apl[loc[1]:]
+	}
+
+	return insertWhere(apl, fmt.Sprintf("where _time > %v", ago))
+}
+
+var timespanPattern = regexp.MustCompile(`^\s*(\d+(?:\.\d+)?)\s*(ms|s|m|h|d)\s*$`)
+
+// the same-length window right before the one starting at the ago(...), so
+// ago(1h) becomes ago(2h) up to ago(1h), along with the length of the window
+func previousWindow(apl string) (string, string, bool) {
+	loc := agoPattern.FindStringIndex(apl)
+
+	if loc == nil {
+		return "", "", false
+	}
+
+	span := strings.TrimSpace(apl[loc[0]+len("ago(") : loc[1]-1])
+	match := timespanPattern.FindStringSubmatch(span)
+
+	if match == nil {
+		return "", "", false
+	}
+
+	length, err := strconv.ParseFloat(match[1], 64)
+
+	if err != nil {
+		return "", "", false
+	}
+
+	doubled := fmt.Sprintf("ago(%v%v)", strconv.FormatFloat(length*2, 'f', -1, 64), match[2])
+	shifted := apl[:loc[0]] + doubled + apl[loc[1]:]
+
+	return insertWhere(shifted, fmt.Sprintf("where _time <= ago(%v)", span)), span, true
+}
+
+// a filter right after the dataset reference, before any other stage
+func insertWhere(apl string, where string) string {
+	apl = strings.TrimSpace(apl)
+
+	if apl == "" {
+		return ""
+	}
+
+	if idx := strings.Index(apl, "|"); idx != -1 {
+		return strings.TrimSpace(apl[:idx]) + " | " + where + " | " + strings.TrimSpace(apl[idx+1:])
+	}
+
+	return apl + " | " + where
+}
+
+// extend these to highlight and complete more of APL
+var APL_OPERATORS = []string{
+	"where",
+	"summarize",
+	"extend",
+	"project",
+	"project-away",
+	"project-keep",
+	"order",
+	"sort",
+	"take",
+	"limit",
+	"top",
+	"count",
+	"distinct",
+	"search",
+	"parse",
+	"union",
+	"join",
+	"by",
+	"asc",
+	"desc",
+	"and",
+	"or",
+	"not",
+}
+
+var APL_FUNCTIONS = []string{
+	"count",
+	"countif",
+	"dcount",
+	"dcountif",
+	"sum",
+	"sumif",
+	"avg",
+	"avgif",
+	"min",
+	"max",
+	"percentile",
+	"percentiles_array",
+	"topk",
+	"histogram",
+	"make_list",
+	"make_set",
+	"bin",
+	"bin_auto",
+	"ago",
+	"now",
+	"contains",
+	"startswith",
+	"endswith",
+	"tostring",
+	"toint",
+	"todouble",
+}
+
+func isAplWordChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-'
+}
+
+func highlightApl(apl string, colors SyntaxColors) string {
+	pipeStyle := lipgloss.NewStyle().Foreground(colors.pipe).Bold(true)
+	operatorStyle := lipgloss.NewStyle().Foreground(colors.keyword).Bold(true)
+	functionStyle := lipgloss.NewStyle().Foreground(colors.function)
+	stringStyle := lipgloss.NewStyle().Foreground(colors.str)
+
+	var b strings.Builder
+
+	runes := []rune(apl)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case r == '|':
+			b.WriteString(pipeStyle.Render("|"))
+			i += 1
+		case r == '\'' || r == '"':
+			// string literal up to the matching quote, or the end of the query
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				if runes[end] == '\\' {
+					end += 1
+				}
+				end += 1
+			}
+			if end >= len(runes) {
+				end = len(runes) - 1
+			}
+
+			b.WriteString(stringStyle.Render(string(runes[i : end+1])))
+			i = end + 1
+		case isAplWordChar(r):
+			end := i
+			for end < len(runes) && isAplWordChar(runes[end]) {
+				end += 1
+			}
+
+			word := string(runes[i:end])
+
+			// functions are only functions when they are called
+			isCall := end < len(runes) && runes[end] == '('
+
+			if isCall && stringInSlice(word, APL_FUNCTIONS) {
+				b.WriteString(functionStyle.Render(word))
+			} else if stringInSlice(word, APL_OPERATORS) {
+				b.WriteString(operatorStyle.Render(word))
+			} else {
+				b.WriteString(word)
+			}
+
+			i = end
+		default:
+			b.WriteRune(r)
+			i += 1
+		}
+	}
+
+	return b.String()
+}
+
+var datasetPattern = regexp.MustCompile(`^\s*(\[\s*(['"])[^'"]+['"]\s*\]|[A-Za-z_][\w.-]*)`)
+
+type aplOpen struct {
+	char rune
+	pos  int
+}
+
+// best-effort sanity check, not a parser: returns a warning or ""
+func validateApl(apl string) string {
+	if !datasetPattern.MatchString(apl) {
+		return "query should start with a dataset, e.g. ['my-dataset']"
+	}
+
+	closers := map[rune]rune{')': '(', ']': '[', '}': '{'}
+	stack := []aplOpen{}
+
+	runes := []rune(apl)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch r {
+		case '\'', '"':
+			start := i
+
+			for i += 1; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' {
+					i += 1
+				}
+			}
+
+			if i >= len(runes) {
+				return fmt.Sprintf("unclosed %c at %v", r, aplPosition(runes, start))
+			}
+		case '(', '[', '{':
+			stack = append(stack, aplOpen{char: r, pos: i})
+		case ')', ']', '}':
+			if len(stack) == 0 || stack[len(stack)-1].char != closers[r] {
+				return fmt.Sprintf("unexpected %c at %v", r, aplPosition(runes, i))
+			}
+
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	if len(stack) > 0 {
+		open := stack[len(stack)-1]
+
+		return fmt.Sprintf("missing closing bracket for %c at %v", open.char, aplPosition(runes, open.pos))
+	}
+
+	return ""
+}
+
+func aplPosition(runes []rune, pos int) string {
+	line, col := 1, 1
+
+	for _, r := range runes[:pos] {
+		if r == '\n' {
+			line += 1
+			col = 1
+		} else {
+			col += 1
+		}
+	}
+
+	return fmt.Sprintf("line %v, col %v", line, col)
+}
+
+const PIPE_INDENT = "  "
+
+// indentation for a line split off of line, with rest being what moves down
+// pipe stages split off an unindented line are indented once so they line up
+func aplIndent(line string, rest string) string {
+	indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+
+	if indent == "" && strings.HasPrefix(strings.TrimSpace(rest), "|") {
+		return PIPE_INDENT
+	}
+
+	return indent
+}
+
+var varPattern = regexp.MustCompile(`\{\{\s*([\w.-]+)\s*\}\}`)
+
+// fills in the {{name}} placeholders of a query template, naming every
+// placeholder that has no value
+func substituteVars(apl string, vars map[string]string) (string, error) {
+	missing := []string{}
+
+	apl = varPattern.ReplaceAllStringFunc(apl, func(placeholder string) string {
+		name := varPattern.FindStringSubmatch(placeholder)[1]
+
+		value, ok := vars[name]
+
+		if !ok {
+			if !stringInSlice(name, missing) {
+				missing = append(missing, name)
+			}
+
+			return placeholder
+		}
+
+		return value
+	})
+
+	if len(missing) > 0 {
+		return apl, fmt.Errorf("no value for %v, set it with --var name=value", strings.Join(missing, ", "))
+	}
+
+	return apl, nil
+}
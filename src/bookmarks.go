@@ -0,0 +1,250 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	BOOKMARKS_CLOSED = iota
+	BOOKMARKS_NAMING
+	BOOKMARKS_CONFIRMING
+	BOOKMARKS_LISTING
+)
+
+func bookmarksPath() (string, error) {
+	dir, err := os.UserConfigDir()
+
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "a-cli", "bookmarks.json"), nil
+}
+
+// a missing file is just an empty set of bookmarks
+func loadBookmarks() (map[string]string, error) {
+	bookmarks := map[string]string{}
+
+	path, err := bookmarksPath()
+
+	if err != nil {
+		return bookmarks, err
+	}
+
+	data, err := os.ReadFile(path)
+
+	if errors.Is(err, os.ErrNotExist) {
+		return bookmarks, nil
+	} else if err != nil {
+		return bookmarks, err
+	}
+
+	if err := json.Unmarshal(data, &bookmarks); err != nil {
+		return map[string]string{}, err
+	}
+
+	return bookmarks, nil
+}
+
+func saveBookmarks(bookmarks map[string]string) error {
+	path, err := bookmarksPath()
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(bookmarks, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+func initBookmarkInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = "Bookmark name: "
+	return ti
+}
+
+func (m *Model) bookmarkNames() []string {
+	var names []string = []string{}
+
+	for name := range m.bookmarks {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func (m *Model) StartBookmarkSave() tea.Cmd {
+	if strings.TrimSpace(m.textarea.Value()) == "" {
+		m.setMsg("Nothing to bookmark")
+		return nil
+	}
+
+	m.bookmarkMode = BOOKMARKS_NAMING
+	m.bookmarkInput.SetValue("")
+	m.textarea.Blur()
+
+	return m.bookmarkInput.Focus()
+}
+
+func (m *Model) StartBookmarkList() {
+	if len(m.bookmarks) == 0 {
+		m.setMsg("No bookmarks saved yet")
+		return
+	}
+
+	m.bookmarkMode = BOOKMARKS_LISTING
+	m.bookmarkIdx = 0
+	m.textarea.Blur()
+}
+
+func (m *Model) CloseBookmarks() tea.Cmd {
+	m.bookmarkMode = BOOKMARKS_CLOSED
+	m.bookmarkInput.Blur()
+
+	return m.textarea.Focus()
+}
+
+func (m *Model) SaveBookmark(name string) tea.Cmd {
+	m.bookmarks[name] = strings.TrimSpace(m.textarea.Value())
+
+	if err := saveBookmarks(m.bookmarks); err != nil {
+		m.setMsg(fmt.Sprintf("Could not save bookmarks: %v", err))
+	} else {
+		m.setMsg(fmt.Sprintf("Saved bookmark %q", name))
+	}
+
+	return m.CloseBookmarks()
+}
+
+func (m *Model) UpdateBookmarks(msg tea.KeyMsg) tea.Cmd {
+	var cmd tea.Cmd
+
+	switch m.bookmarkMode {
+	case BOOKMARKS_NAMING:
+		switch msg.String() {
+		case "esc":
+			return m.CloseBookmarks()
+		case "enter":
+			name := strings.TrimSpace(m.bookmarkInput.Value())
+
+			if name == "" {
+				return nil
+			}
+
+			if _, ok := m.bookmarks[name]; ok {
+				m.bookmarkMode = BOOKMARKS_CONFIRMING
+				return nil
+			}
+
+			return m.SaveBookmark(name)
+		default:
+			m.bookmarkInput, cmd = m.bookmarkInput.Update(msg)
+		}
+	case BOOKMARKS_CONFIRMING:
+		switch msg.String() {
+		case "y":
+			return m.SaveBookmark(strings.TrimSpace(m.bookmarkInput.Value()))
+		case "n", "esc":
+			// back to picking another name
+			m.bookmarkMode = BOOKMARKS_NAMING
+		}
+	case BOOKMARKS_LISTING:
+		names := m.bookmarkNames()
+
+		switch msg.String() {
+		case "esc":
+			return m.CloseBookmarks()
+		case "up", "k":
+			if m.bookmarkIdx > 0 {
+				m.bookmarkIdx -= 1
+			}
+		case "down", "j":
+			if m.bookmarkIdx < len(names)-1 {
+				m.bookmarkIdx += 1
+			}
+		case "enter":
+			m.textarea.SetValue(m.bookmarks[names[m.bookmarkIdx]])
+
+			return m.CloseBookmarks()
+		case "d":
+			delete(m.bookmarks, names[m.bookmarkIdx])
+
+			if err := saveBookmarks(m.bookmarks); err != nil {
+				m.setMsg(fmt.Sprintf("Could not save bookmarks: %v", err))
+			} else {
+				m.setMsg(fmt.Sprintf("Deleted bookmark %q", names[m.bookmarkIdx]))
+			}
+
+			if len(m.bookmarks) == 0 {
+				return m.CloseBookmarks()
+			}
+
+			if m.bookmarkIdx >= len(m.bookmarks) {
+				m.bookmarkIdx = len(m.bookmarks) - 1
+			}
+		}
+	}
+
+	return cmd
+}
+
+func (m Model) ViewBookmarks() string {
+	switch m.bookmarkMode {
+	case BOOKMARKS_NAMING:
+		return tableStyle.Render(m.bookmarkInput.View())
+	case BOOKMARKS_CONFIRMING:
+		return tableStyle.Render(fmt.Sprintf("Bookmark %q already exists, overwrite? (y/n)", strings.TrimSpace(m.bookmarkInput.Value())))
+	case BOOKMARKS_LISTING:
+		nameStyle := lipgloss.NewStyle().Width(24)
+		selectedStyle := nameStyle.Copy().
+			Foreground(m.theme.highlightForeground).
+			Background(m.theme.highlightBackground)
+		aplStyle := lipgloss.NewStyle().Foreground(m.theme.hintColor)
+
+		var lines []string = []string{
+			"Bookmarks (enter loads, d deletes, esc closes)",
+		}
+
+		for i, name := range m.bookmarkNames() {
+			style := nameStyle
+
+			if i == m.bookmarkIdx {
+				style = selectedStyle
+			}
+
+			apl := strings.ReplaceAll(m.bookmarks[name], "\n", " ")
+
+			lines = append(lines, lipgloss.JoinHorizontal(
+				lipgloss.Left,
+				style.Render(name),
+				" ",
+				aplStyle.Render(apl),
+			))
+		}
+
+		return tableStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+	}
+
+	return ""
+}
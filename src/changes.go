@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	axiomQuery "github.com/axiomhq/axiom-go/axiom/query"
+	"github.com/charmbracelet/bubbles/table"
+)
+
+// rows new (or changed) in the latest refresh get the first marker, the one
+// before that the second, older rows none
+var NEW_ROW_MARKERS = []string{"●", "○"}
+
+var NEW_ROW_COLUMN = table.Column{Title: "", Width: 1}
+
+// how many refreshes each row has been around for, by a hash of its contents
+type rowAges map[uint64]int
+
+// rows of the first result of a query aren't new, only what refreshes add
+func ageRows(previous rowAges, keys []uint64, refreshed bool) rowAges {
+	ages := rowAges{}
+
+	for _, key := range keys {
+		age, seen := previous[key]
+
+		switch {
+		case !refreshed:
+			ages[key] = len(NEW_ROW_MARKERS)
+		case seen:
+			ages[key] = age + 1
+		default:
+			ages[key] = 0
+		}
+	}
+
+	return ages
+}
+
+func (ages rowAges) marker(key uint64) string {
+	age, ok := ages[key]
+
+	if !ok || age >= len(NEW_ROW_MARKERS) {
+		return ""
+	}
+
+	return NEW_ROW_MARKERS[age]
+}
+
+// a total whose values changed hashes differently, so it shows up as new
+func totalKey(total axiomQuery.EntryGroup) uint64 {
+	hash := fnv.New64a()
+
+	fmt.Fprintf(hash, "%v|", total.Group)
+
+	for _, aggregation := range total.Aggregations {
+		fmt.Fprintf(hash, "%v=%v|", aggregation.Alias, aggregation.Value)
+	}
+
+	return hash.Sum64()
+}
+
+// only results coming in advance the ages, re-sorting or filtering the
+// tables doesn't
+func (m *Model) UpdateRowAges(result *axiomQuery.Result) {
+	matchKeys := []uint64{}
+	totalKeys := []uint64{}
+
+	if result != nil {
+		for _, match := range result.Matches {
+			matchKeys = append(matchKeys, matchKey(match))
+		}
+
+		for _, total := range result.Buckets.Totals {
+			totalKeys = append(totalKeys, totalKey(total))
+		}
+	}
+
+	m.matchAges = ageRows(m.matchAges, matchKeys, m.autoRefresh)
+	m.totalAges = ageRows(m.totalAges, totalKeys, m.autoRefresh)
+}
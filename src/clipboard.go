@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// without a clipboard (no xclip, xsel or wl-copy, a remote shell) the query
+// is shown to be copied by hand instead
+func (m Model) CopyQuery() tea.Cmd {
+	apl := strings.TrimSpace(m.textarea.Value())
+
+	if apl == "" {
+		return nil
+	}
+
+	return func() tea.Msg {
+		msg := "Copied the query to the clipboard"
+
+		if clipboard.Unsupported {
+			msg = fmt.Sprintf("No clipboard available, the query is: %v", apl)
+		} else if err := clipboard.WriteAll(apl); err != nil {
+			msg = fmt.Sprintf("Could not copy the query (%v), it is: %v", err, apl)
+		}
+
+		return Msg{
+			update: func(m *Model) {
+				m.setMsg(msg)
+			},
+		}
+	}
+}
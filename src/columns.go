@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// the pane's padding and border on both sides
+const MATCHES_PANE_OVERHEAD = 4
+
+// cells are padded by a space on both sides
+func cellWidth(column table.Column) int {
+	return column.Width + 2
+}
+
+// the columns of the matches that fit the window at the current offset.
+// _time and the new row marker stay put, there's always one column between
+// them however wide it is.
+func (m Model) visibleMatchColumns() ([]int, bool, bool) {
+	columns := m.matchesColumns
+	last := len(columns) - 1
+	offset := maxInt(0, minInt(m.matchColumnOffset, last-2))
+
+	if m.width == 0 || last < 2 {
+		offset = 0
+	}
+
+	room := m.width - MATCHES_PANE_OVERHEAD - cellWidth(columns[0]) - cellWidth(columns[last])
+	shown := []int{0}
+
+	idx := 1 + offset
+
+	for ; idx < last; idx++ {
+		if m.width > 0 && cellWidth(columns[idx]) > room && len(shown) > 1 {
+			break
+		}
+
+		room -= cellWidth(columns[idx])
+		shown = append(shown, idx)
+	}
+
+	return append(shown, last), offset > 0, idx < last
+}
+
+// only while there's more to see that way
+func (m *Model) ScrollMatchColumns(step int) {
+	if m.matchesTable == nil {
+		return
+	}
+
+	_, moreLeft, moreRight := m.visibleMatchColumns()
+
+	if (step < 0 && moreLeft) || (step > 0 && moreRight) {
+		m.matchColumnOffset = maxInt(0, minInt(m.matchColumnOffset, len(m.matchesColumns)-3)+step)
+	}
+}
+
+// a copy of the table with only the columns that fit
+func (m Model) scrolledMatchesTable() (table.Model, bool, bool) {
+	t := *m.matchesTable
+	shown, moreLeft, moreRight := m.visibleMatchColumns()
+
+	if !moreLeft && !moreRight {
+		return t, false, false
+	}
+
+	columns := []table.Column{}
+
+	for _, idx := range shown {
+		columns = append(columns, m.matchesColumns[idx])
+	}
+
+	rows := []table.Row{}
+
+	for _, row := range m.matchesTable.Rows() {
+		cells := table.Row{}
+
+		for _, idx := range shown {
+			cells = append(cells, row[idx])
+		}
+
+		rows = append(rows, cells)
+	}
+
+	// rows have to shrink first, they're rendered against the columns
+	t.SetRows(rows)
+	t.SetColumns(columns)
+
+	return t, moreLeft, moreRight
+}
+
+func (m Model) ViewMatchColumnsHint(moreLeft bool, moreRight bool) string {
+	if !moreLeft && !moreRight {
+		return ""
+	}
+
+	shown, _, _ := m.visibleMatchColumns()
+	total := len(m.matchesColumns)
+	left := shown[1] - 1
+	right := total - 1 - shown[len(shown)-2] - 1
+
+	hints := []string{}
+
+	if moreLeft {
+		hints = append(hints, fmt.Sprintf("◀ %v more", left))
+	}
+
+	hints = append(hints, "←/h →/l scroll the columns")
+
+	if moreRight {
+		hints = append(hints, fmt.Sprintf("%v more ▶", right))
+	}
+
+	return lipgloss.NewStyle().
+		Foreground(m.theme.hintColor).
+		Render(strings.Join(hints, "  "))
+}
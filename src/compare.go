@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// appended to a group for its series in the previous window
+const PREVIOUS_SUFFIX = " (previous)"
+
+var errNoTimeRange = errors.New("the query has no ago() time range to shift")
+
+// the previous window is fetched along with the next run, and dropped right
+// away when turned off
+func (m *Model) ToggleCompare() tea.Cmd {
+	m.compare = !m.compare
+
+	if !m.compare {
+		m.previous = nil
+		m.previousSpan = ""
+		m.UpdateGraphs(m.query.result)
+		m.setMsg("Not comparing with the previous window")
+
+		return nil
+	}
+
+	if _, _, ok := previousWindow(m.query.apl); !ok {
+		m.setMsg(fmt.Sprintf("Can't compare, %v", errNoTimeRange))
+	}
+
+	if m.query.apl == "" {
+		return nil
+	}
+
+	return m.RefreshQuery()
+}
+
+// a tail refresh only queries new matches, so the previous window of the
+// last full run is kept
+func (m *Model) UpdatePrevious(msg ResultMsg) {
+	if !m.compare || msg.err != nil || !msg.since.IsZero() {
+		return
+	}
+
+	m.previous = msg.previous
+	m.previousSpan = ""
+
+	if msg.previousErr != nil {
+		m.setMsg(fmt.Sprintf("Can't compare, %v", msg.previousErr))
+		return
+	}
+
+	_, m.previousSpan, _ = previousWindow(msg.apl)
+}
+
+// one dimmed series per group in color, lined up with the current window by
+// interval rather than by time
+func (m *Model) addPreviousSeries(graphs []GraphData) {
+	if m.previous == nil || m.queryMeta == nil {
+		return
+	}
+
+	for graphIdx, graph := range graphs {
+		seriesIdx := map[string]int{}
+		var groups []string
+		var data [][]float64
+
+		for _, group := range graph.groups {
+			if group == OTHER_GROUP || m.groupDimmed(group) {
+				continue
+			}
+
+			seriesIdx[group] = len(groups)
+			groups = append(groups, group)
+			data = append(data, make([]float64, m.queryMeta.intervals))
+		}
+
+		hasValue := make([]bool, len(groups))
+
+		for intervalIdx, interval := range m.previous.Buckets.Series {
+			if intervalIdx >= m.queryMeta.intervals {
+				break
+			}
+
+			for _, group := range interval.Groups {
+				idx, ok := seriesIdx[getGroupKey(m.queryMeta.orderedGroupKeys, group.Group)]
+
+				if !ok || graphIdx >= len(group.Aggregations) {
+					continue
+				}
+
+				value := toFloat64(group.Aggregations[graphIdx].Value)
+				data[idx][intervalIdx] = value
+
+				if !math.IsNaN(value) {
+					hasValue[idx] = true
+				}
+			}
+		}
+
+		for i, group := range groups {
+			if !hasValue[i] {
+				continue
+			}
+
+			graph.groups = append(graph.groups, group)
+			graph.labels = append(graph.labels, group+PREVIOUS_SUFFIX)
+			graph.data = append(graph.data, data[i])
+			graph.colors = append(graph.colors, m.theme.dimColor)
+		}
+
+		graphs[graphIdx] = graph
+	}
+}
+
+func (m Model) ViewPreviousLegend() string {
+	if m.previous == nil || m.previousSpan == "" {
+		return ""
+	}
+
+	return lipgloss.NewStyle().
+		Foreground(ansiColor(m.theme.dimColor)).
+		PaddingRight(2).
+		Render(fmt.Sprintf("■ <group>%v: the %v before", PREVIOUS_SUFFIX, m.previousSpan))
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// operators complete with a trailing space, functions with their opening paren,
+// a word in both lists completes as the operator
+func aplCompletions(word string) []string {
+	seen := map[string]bool{}
+	completions := []string{}
+
+	add := func(keywords []string, suffix string) {
+		for _, keyword := range keywords {
+			if seen[keyword] || !strings.HasPrefix(keyword, word) || keyword == word {
+				continue
+			}
+
+			seen[keyword] = true
+			completions = append(completions, keyword+suffix)
+		}
+	}
+
+	add(APL_OPERATORS, " ")
+	add(APL_FUNCTIONS, "(")
+
+	return completions
+}
+
+const MAX_DATASET_HINTS = 10
+
+type DatasetsMsg struct {
+	names []string
+	err   error
+}
+
+// the partial dataset name when the cursor is inside a [" or [' dataset
+// reference that isn't closed yet
+func datasetContext(before string) (string, string, bool) {
+	idx := maxInt(strings.LastIndex(before, `["`), strings.LastIndex(before, `['`))
+
+	if idx == -1 {
+		return "", "", false
+	}
+
+	quote := before[idx+1 : idx+2]
+	partial := before[idx+2:]
+
+	if strings.ContainsAny(partial, `"']`) {
+		return "", "", false
+	}
+
+	return partial, quote, true
+}
+
+// datasets complete with the closing quote and bracket
+func datasetCompletions(datasets []string, partial string, quote string) []string {
+	completions := []string{}
+
+	for _, name := range datasets {
+		if strings.HasPrefix(strings.ToLower(name), strings.ToLower(partial)) {
+			completions = append(completions, name+quote+"]")
+		}
+	}
+
+	return completions
+}
+
+// the dataset list is only fetched the first time a dataset reference is typed
+func (m *Model) LoadDatasets() tea.Cmd {
+	lister, ok := m.client.(DatasetLister)
+
+	if m.datasets != nil || m.datasetsLoading || !ok {
+		return nil
+	}
+
+	before, _ := m.cursorLine()
+
+	if _, _, ok := datasetContext(before); !ok {
+		return nil
+	}
+
+	m.datasetsLoading = true
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		names, err := lister.ListDatasets(ctx)
+
+		return DatasetsMsg{names: names, err: err}
+	}
+}
+
+// a failed fetch caches an empty list so typing doesn't refetch on every key
+func (m *Model) SetDatasets(msg DatasetsMsg) {
+	m.datasetsLoading = false
+	m.datasets = msg.names
+
+	if msg.err != nil {
+		m.datasets = []string{}
+		m.setMsg(fmt.Sprintf("Could not load datasets: %v", msg.err))
+	}
+}
+
+func (m Model) wordBeforeCursor() string {
+	before, _ := m.cursorLine()
+	runes := []rune(before)
+
+	start := len(runes)
+	for start > 0 && isAplWordChar(runes[start-1]) {
+		start -= 1
+	}
+
+	return string(runes[start:])
+}
+
+// tab completes the word before the cursor, with several candidates repeated
+// tabs cycle through them
+func (m *Model) Complete() {
+	if len(m.completions) > 0 {
+		m.removeBeforeCursor(m.completionInserted)
+
+		m.completionIdx = (m.completionIdx + 1) % len(m.completions)
+		m.insertCompletion()
+
+		return
+	}
+
+	before, _ := m.cursorLine()
+
+	var word string
+	var completions []string
+
+	if partial, quote, ok := datasetContext(before); ok {
+		word = partial
+		completions = datasetCompletions(m.datasets, partial, quote)
+	} else {
+		word = m.wordBeforeCursor()
+
+		if word == "" {
+			return
+		}
+
+		completions = aplCompletions(word)
+	}
+
+	if len(completions) == 0 {
+		return
+	}
+
+	m.completions = completions
+	m.completionIdx = 0
+	m.removeBeforeCursor(word)
+	m.insertCompletion()
+
+	// nothing to cycle through
+	if len(completions) == 1 {
+		m.CloseCompletions()
+	}
+}
+
+// in place of the word typed so far rather than after it, datasets match it
+// whatever their case
+func (m *Model) insertCompletion() {
+	m.completionInserted = m.completions[m.completionIdx]
+	m.textarea.InsertString(m.completionInserted)
+}
+
+// the textarea has no way to delete text, so backspace over it instead
+func (m *Model) removeBeforeCursor(text string) {
+	for range []rune(text) {
+		m.textarea, _ = m.textarea.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+}
+
+func (m *Model) CloseCompletions() {
+	m.completions = nil
+	m.completionInserted = ""
+	m.completionIdx = 0
+}
+
+// the datasets matching a reference being typed are hinted before tab is pressed
+func (m Model) ViewDatasetHints() string {
+	before, _ := m.cursorLine()
+	partial, quote, ok := datasetContext(before)
+
+	if !ok || !m.textarea.Focused() {
+		return ""
+	}
+
+	hintStyle := lipgloss.NewStyle().PaddingLeft(1).Foreground(m.theme.hintColor)
+
+	if m.datasetsLoading {
+		return hintStyle.Render("loading datasets...")
+	}
+
+	completions := datasetCompletions(m.datasets, partial, quote)
+
+	if len(completions) == 0 {
+		return ""
+	}
+
+	names := []string{}
+
+	for _, completion := range completions[:minInt(len(completions), MAX_DATASET_HINTS)] {
+		names = append(names, strings.TrimSuffix(completion, quote+"]"))
+	}
+
+	if len(completions) > MAX_DATASET_HINTS {
+		names = append(names, fmt.Sprintf("(+%v more)", len(completions)-MAX_DATASET_HINTS))
+	}
+
+	return hintStyle.Render("tab: " + strings.Join(names, "  "))
+}
+
+func (m Model) ViewCompletions() string {
+	if len(m.completions) == 0 {
+		return m.ViewDatasetHints()
+	}
+
+	candidateStyle := lipgloss.NewStyle().Foreground(m.theme.hintColor)
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(m.theme.highlightForeground).
+		Background(m.theme.highlightBackground)
+
+	var candidates []string
+
+	for i, completion := range m.completions {
+		style := candidateStyle
+
+		if i == m.completionIdx {
+			style = selectedStyle
+		}
+
+		candidates = append(candidates, style.Render(strings.TrimSpace(completion)))
+	}
+
+	return lipgloss.NewStyle().
+		PaddingLeft(1).
+		Render(candidateStyle.Render("tab: ") + strings.Join(candidates, "  "))
+}
@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestComplete(t *testing.T) {
+	datasets := []string{"axiom-logs", "axiom-traces", "Billing"}
+
+	tests := []struct {
+		typed string
+		tabs  int
+		want  string
+	}{
+		{"['logs'] | summ", 1, "['logs'] | summarize "},
+		{`["axiom-t`, 1, `["axiom-traces"]`},
+		// typed in another case than the dataset is named
+		{`["Axiom-T`, 1, `["axiom-traces"]`},
+		{`['bil`, 1, `['Billing']`},
+		{`["AXI`, 1, `["axiom-logs"]`},
+		{`["AXI`, 2, `["axiom-traces"]`},
+		{`["AXI`, 3, `["axiom-logs"]`},
+	}
+
+	for _, test := range tests {
+		m := testModel(nil)
+		m.datasets = datasets
+		m.textarea.SetValue(test.typed)
+
+		for i := 0; i < test.tabs; i++ {
+			m.Complete()
+		}
+
+		if got := m.textarea.Value(); got != test.want {
+			t.Errorf("%q after %v tabs = %q, want %q", test.typed, test.tabs, got, test.want)
+		}
+	}
+}
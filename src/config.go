@@ -0,0 +1,273 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	axiomQuery "github.com/axiomhq/axiom-go/axiom/query"
+	"github.com/muesli/termenv"
+)
+
+const AUTO_PRECISION = -1
+
+var OUTPUT_FORMATS = []string{"table", "json", "csv"}
+
+type Config struct {
+	theme            Theme
+	precision        int
+	refreshInterval  int
+	location         *time.Location
+	warning          string
+	timeFormat       string
+	noColor          bool
+	units            bool
+	numbers          NumberFormat
+	topN             int
+	spinnerType      string
+	noSplash         bool
+	retries          int
+	retryDelay       time.Duration
+	replay           *axiomQuery.Result
+	recordDir        string
+	vars             map[string]string
+	thresholds       map[string]float64
+	bell             bool
+	matchLimit       int
+	tail             bool
+	detailFields     []string
+	detailFieldsOnly bool
+	matchColumns     []string
+	query            string
+	format           string
+	watch            string
+	graphHeight      int
+	prefs            Prefs
+	viewMode         string
+}
+
+// --var can be repeated, each one setting a {{name}} placeholder
+type varsFlag map[string]string
+
+func (v varsFlag) String() string {
+	return fmt.Sprint(map[string]string(v))
+}
+
+func (v varsFlag) Set(value string) error {
+	name, val, ok := strings.Cut(value, "=")
+
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("expected name=value, got %q", value)
+	}
+
+	v[strings.TrimSpace(name)] = val
+
+	return nil
+}
+
+// --threshold can be repeated too, one per op
+type thresholdsFlag map[string]float64
+
+func (t thresholdsFlag) String() string {
+	return fmt.Sprint(map[string]float64(t))
+}
+
+func (t thresholdsFlag) Set(value string) error {
+	op, val, ok := strings.Cut(value, "=")
+
+	if !ok || strings.TrimSpace(op) == "" {
+		return fmt.Errorf("expected op=value, got %q", value)
+	}
+
+	threshold, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+
+	if err != nil {
+		return fmt.Errorf("expected a number for %v, got %q", strings.TrimSpace(op), val)
+	}
+
+	t[strings.TrimSpace(op)] = threshold
+
+	return nil
+}
+
+func parseConfig() Config {
+	vars := varsFlag{}
+	flag.Var(vars, "var", "value for a {{name}} placeholder in the query, as name=value (repeatable)")
+	thresholds := thresholdsFlag{}
+	flag.Var(thresholds, "threshold", "mark an op on its graph when it goes over a value, as op=value with the op named as in the graph title (repeatable)")
+	bell := flag.Bool("bell", false, "ring the terminal bell and flash the graph when an op goes over its --threshold")
+	themeName := flag.String("theme", "default", fmt.Sprintf("color theme (%v)", strings.Join(themeNames(), ", ")))
+	precision := flag.Int("precision", AUTO_PRECISION, "decimals shown on graphs (0-4), picked from the data when unset")
+	utc := flag.Bool("utc", false, "show times in UTC instead of the local timezone")
+	local := flag.Bool("local", false, "show times in the local timezone (the default)")
+	timezone := flag.String("timezone", "", "show times in an IANA timezone, e.g. America/New_York")
+	noGroupNumbers := flag.Bool("no-group-numbers", false, "don't add thousands separators to whole numbers")
+	locale := flag.String("locale", DEFAULT_LOCALE, "format numbers for a locale, e.g. de or fr-CH, auto reads it from LC_ALL, LC_NUMERIC or LANG")
+	refreshInterval := flag.Int("refresh", DEFAULT_REFRESH_INTERVAL, fmt.Sprintf("seconds between a result and the next refresh, at least %v", MIN_REFRESH_INTERVAL))
+	graphHeight := flag.Int("graph-height", AUTO_GRAPH_HEIGHT, fmt.Sprintf("rows per graph, at least %v, picked from the window when unset", MIN_GRAPH_HEIGHT))
+	topN := flag.Int("top", 0, "only graph the top N groups by their total, 0 graphs all")
+	spinnerType := flag.String("spinner", DEFAULT_SPINNER, fmt.Sprintf("spinner shown while querying (%v)", strings.Join(spinnerNames(), ", ")))
+	units := flag.Bool("units", false, "format durations and byte counts humanely, based on the op name")
+	retries := flag.Int("retries", 2, "times a query failing with a server or network error is retried")
+	retryDelay := flag.Duration("retry-delay", 500*time.Millisecond, "delay before the first retry, doubled for each one after")
+	replayPath := flag.String("replay", "", "answer every query with the result saved in FILE instead of calling Axiom")
+	recordDir := flag.String("record", "", "save every result as a timestamped JSON file in DIR, for --replay")
+	matchLimit := flag.Int("match-limit", 1000, "warn when a result has this many matches, as it's likely cut off, 0 never warns")
+	tail := flag.Bool("tail", false, "on refresh only fetch newer matches and add them on top, like tail -f")
+	fields := flag.String("fields", "", "comma separated fields shown first in match details, in order")
+	fieldsOnly := flag.Bool("fields-only", false, "only show the --fields in match details")
+	columns := flag.String("columns", "", "comma separated fields shown as matches table columns, in order, instead of all of them")
+	query := flag.String("query", "", "run the APL query once and print the result instead of starting the TUI")
+	format := flag.String("format", "table", fmt.Sprintf("with --query, print the result as %v", strings.Join(OUTPUT_FORMATS, ", ")))
+	jsonOutput := flag.Bool("json", false, "with --query, same as --format json")
+	watch := flag.String("watch", "", "run the APL in FILE and run it again whenever the file changes")
+	noSplash := flag.Bool("no-splash", false, "skip the splash screen and start at the query")
+	noColor := flag.Bool("no-color", false, "disable all colors (also set by NO_COLOR)")
+	timeFormat := flag.String("time-format", DEFAULT_TIME_FORMAT, "_time format: a Go layout or one of rfc3339, kitchen, unix, relative")
+
+	flag.Parse()
+
+	prefs, prefsErr := loadPrefs()
+	prefs.applyToFlags()
+
+	theme, err := getTheme(*themeName)
+
+	if err != nil {
+		exitWithError(err)
+	}
+
+	if *precision != AUTO_PRECISION && (*precision < 0 || *precision > 4) {
+		exitWithError(fmt.Errorf("precision must be between 0 and 4, got %v", *precision))
+	}
+
+	if *graphHeight != AUTO_GRAPH_HEIGHT && *graphHeight < MIN_GRAPH_HEIGHT {
+		exitWithError(fmt.Errorf("graph height must be at least %v, got %v", MIN_GRAPH_HEIGHT, *graphHeight))
+	}
+
+	if *refreshInterval < MIN_REFRESH_INTERVAL {
+		exitWithError(fmt.Errorf("refresh interval must be at least %v seconds, got %v", MIN_REFRESH_INTERVAL, *refreshInterval))
+	}
+
+	if _, ok := SPINNERS[*spinnerType]; !ok {
+		exitWithError(fmt.Errorf("unknown spinner %q, expected one of: %v", *spinnerType, strings.Join(spinnerNames(), ", ")))
+	}
+
+	if *retries < 0 {
+		exitWithError(fmt.Errorf("retries can't be negative, got %v", *retries))
+	}
+
+	var replay *axiomQuery.Result
+
+	if *replayPath != "" {
+		replay, err = loadReplay(*replayPath)
+
+		if err != nil {
+			exitWithError(err)
+		}
+	}
+
+	if *jsonOutput {
+		*format = "json"
+	}
+
+	if !stringInSlice(*format, OUTPUT_FORMATS) {
+		exitWithError(fmt.Errorf("unknown format %q, expected one of: %v", *format, strings.Join(OUTPUT_FORMATS, ", ")))
+	}
+
+	printer, err := parseLocale(*locale)
+
+	if err != nil {
+		exitWithError(err)
+	}
+
+	location, warning := loadLocation(*timezone, *utc, *local)
+
+	if prefsErr != nil {
+		prefsWarning := fmt.Sprintf("Ignoring preferences: %v", prefsErr)
+
+		if warning != "" {
+			prefsWarning = fmt.Sprintf("%v. %v", prefsWarning, warning)
+		}
+
+		warning = prefsWarning
+	}
+
+	viewMode := VIEW_MODES[0]
+
+	if prefs.View != "" {
+		viewMode = prefs.View
+	}
+
+	return Config{
+		theme:            theme,
+		precision:        *precision,
+		refreshInterval:  *refreshInterval,
+		location:         location,
+		warning:          warning,
+		timeFormat:       *timeFormat,
+		noColor:          *noColor || termenv.EnvNoColor(),
+		units:            *units,
+		numbers:          NumberFormat{printer: printer, group: !*noGroupNumbers},
+		topN:             *topN,
+		spinnerType:      *spinnerType,
+		noSplash:         *noSplash,
+		retries:          *retries,
+		retryDelay:       *retryDelay,
+		replay:           replay,
+		recordDir:        *recordDir,
+		vars:             vars,
+		thresholds:       thresholds,
+		bell:             *bell,
+		matchLimit:       *matchLimit,
+		tail:             *tail,
+		detailFields:     splitList(*fields),
+		detailFieldsOnly: *fieldsOnly,
+		matchColumns:     splitList(*columns),
+		query:            strings.TrimSpace(*query),
+		format:           *format,
+		watch:            *watch,
+		graphHeight:      *graphHeight,
+		prefs:            prefs,
+		viewMode:         viewMode,
+	}
+}
+
+// an explicit timezone wins over --utc, which wins over --local
+func loadLocation(timezone string, utc bool, local bool) (*time.Location, string) {
+	if timezone != "" {
+		location, err := time.LoadLocation(timezone)
+
+		if err != nil {
+			return time.UTC, fmt.Sprintf("Unknown timezone %q, showing times in UTC", timezone)
+		}
+
+		return location, ""
+	}
+
+	if utc && !local {
+		return time.UTC, ""
+	}
+
+	return time.Local, ""
+}
+
+// a comma separated flag value, ignoring blanks
+func splitList(value string) []string {
+	items := []string{}
+
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+func exitWithError(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
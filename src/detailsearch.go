@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func initDetailSearchInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = "/"
+	ti.Placeholder = "search details..."
+	return ti
+}
+
+func (m *Model) StartDetailSearch() tea.Cmd {
+	m.detailSearching = true
+	m.detailSearchInput.SetValue(m.detailSearch)
+	m.detailSearchInput.CursorEnd()
+
+	return m.detailSearchInput.Focus()
+}
+
+// searches as you type, enter keeps the search and esc clears it
+func (m *Model) UpdateDetailSearch(msg tea.KeyMsg) tea.Cmd {
+	var cmd tea.Cmd
+
+	switch msg.String() {
+	case "enter":
+		m.detailSearching = false
+		m.detailSearchInput.Blur()
+
+		return nil
+	case "esc":
+		m.detailSearching = false
+		m.detailSearchInput.Blur()
+		m.detailSearchInput.SetValue("")
+	default:
+		m.detailSearchInput, cmd = m.detailSearchInput.Update(msg)
+	}
+
+	m.detailSearch = m.detailSearchInput.Value()
+	m.detailSearchIdx = 0
+	m.RenderMatchDetails()
+
+	return cmd
+}
+
+// n and N move between the lines the search term is on, wrapping around
+func (m *Model) JumpDetailSearch(step int) {
+	if len(m.detailSearchLines) == 0 {
+		return
+	}
+
+	m.detailSearchIdx = (m.detailSearchIdx + step + len(m.detailSearchLines)) % len(m.detailSearchLines)
+	m.RenderMatchDetails()
+}
+
+// the lines with the search term are shown with it highlighted instead of
+// colorized, the current one stands out from the rest
+func (m *Model) RenderMatchDetails() {
+	lines := strings.Split(m.detailsJson, "\n")
+	term := m.detailSearch
+
+	m.detailSearchLines = []int{}
+
+	for i, line := range lines {
+		if start, _ := indexFold(line, term); start != -1 {
+			m.detailSearchLines = append(m.detailSearchLines, i)
+		}
+	}
+
+	if m.detailSearchIdx >= len(m.detailSearchLines) {
+		m.detailSearchIdx = 0
+	}
+
+	matchStyle := lipgloss.NewStyle().Reverse(true)
+	currentStyle := lipgloss.NewStyle().
+		Foreground(m.theme.highlightForeground).
+		Background(m.theme.highlightBackground)
+
+	rendered := make([]string, len(lines))
+
+	for i, line := range lines {
+		rendered[i] = colorizeJson(line, m.theme.syntax)
+	}
+
+	for idx, lineIdx := range m.detailSearchLines {
+		style := matchStyle
+
+		if idx == m.detailSearchIdx {
+			style = currentStyle
+		}
+
+		rendered[lineIdx] = highlightTerm(lines[lineIdx], term, style)
+	}
+
+	m.detailsViewport.SetContent(strings.Join(rendered, "\n"))
+
+	if len(m.detailSearchLines) > 0 {
+		m.detailsViewport.SetYOffset(m.detailSearchLines[m.detailSearchIdx])
+	}
+}
+
+// every case insensitive occurrence of term in line, rendered with style
+func highlightTerm(line string, term string, style lipgloss.Style) string {
+	var b strings.Builder
+
+	for {
+		start, end := indexFold(line, term)
+
+		if start == -1 {
+			b.WriteString(line)
+			break
+		}
+
+		b.WriteString(line[:start])
+		b.WriteString(style.Render(line[start:end]))
+
+		line = line[end:]
+	}
+
+	return b.String()
+}
+
+// the byte offsets in s of the first case insensitive occurrence of term.
+// Compared rune by rune in s itself, as lowercasing can change the length
+// of a string and with it every offset after.
+func indexFold(s string, term string) (int, int) {
+	if term == "" {
+		return -1, -1
+	}
+
+	runes := utf8.RuneCountInString(term)
+
+	for start := 0; start < len(s); {
+		end := start
+
+		for n := 0; n < runes && end < len(s); n++ {
+			_, size := utf8.DecodeRuneInString(s[end:])
+			end += size
+		}
+
+		if strings.EqualFold(s[start:end], term) {
+			return start, end
+		}
+
+		_, size := utf8.DecodeRuneInString(s[start:])
+		start += size
+	}
+
+	return -1, -1
+}
+
+func (m Model) ViewDetailSearch() string {
+	if m.detailSearching {
+		return m.detailSearchInput.View()
+	}
+
+	if m.detailSearch == "" {
+		return ""
+	}
+
+	if len(m.detailSearchLines) == 0 {
+		return fmt.Sprintf("Search: %v (no matches)", m.detailSearch)
+	}
+
+	return fmt.Sprintf("Search: %v (%v of %v, n/N for next/previous)", m.detailSearch, m.detailSearchIdx+1, len(m.detailSearchLines))
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestHighlightTerm(t *testing.T) {
+	style := lipgloss.NewStyle().Transform(func(s string) string {
+		return "[" + s + "]"
+	})
+
+	tests := []struct {
+		line string
+		term string
+		want string
+	}{
+		{`"method": "GET"`, "get", `"method": "[GET]"`},
+		{"Get get GET", "get", "[Get] [get] [GET]"},
+		{"no match here", "xyz", "no match here"},
+		// lowercased these get longer, so offsets into the lowercased line
+		// are off in the line itself
+		{"ȺȺȺȺ x", "x", "ȺȺȺȺ [x]"},
+		{"İstanbul is big", "big", "İstanbul is [big]"},
+		{"ȺȺ", "ⱥ", "[Ⱥ][Ⱥ]"},
+		{"anything", "", "anything"},
+	}
+
+	for _, test := range tests {
+		if got := highlightTerm(test.line, test.term, style); got != test.want {
+			t.Errorf("highlightTerm(%q, %q) = %q, want %q", test.line, test.term, got, test.want)
+		}
+	}
+}
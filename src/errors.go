@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/axiomhq/axiom-go/axiom"
+)
+
+const (
+	ERROR_OTHER = iota
+	ERROR_AUTH
+	ERROR_PERMISSION
+	ERROR_NOT_FOUND
+	ERROR_QUERY
+	ERROR_LIMIT
+	ERROR_SERVER
+	ERROR_NETWORK
+	ERROR_TIMEOUT
+)
+
+var ERROR_HINTS = map[int]string{
+	ERROR_AUTH:       "Check that AXIOM_TOKEN (and AXIOM_ORG_ID for personal tokens) is set and still valid.",
+	ERROR_PERMISSION: "The token is valid but can't query this dataset, check its permissions.",
+	ERROR_NOT_FOUND:  "Check the dataset name in the query.",
+	ERROR_QUERY:      "Check the APL syntax of the query.",
+	ERROR_LIMIT:      "Wait a moment before running the query again.",
+	ERROR_SERVER:     "Axiom had a problem running the query, try again.",
+	ERROR_NETWORK:    "Check the network connection and AXIOM_URL.",
+	ERROR_TIMEOUT:    "Narrow the time range or aggregate more to speed the query up.",
+}
+
+func classifyError(err error) int {
+	var apiErr *axiom.Error
+	var limitErr *axiom.LimitError
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, axiom.ErrUnauthenticated):
+		return ERROR_AUTH
+	case errors.Is(err, axiom.ErrUnauthorized):
+		return ERROR_PERMISSION
+	case errors.Is(err, axiom.ErrNotFound):
+		return ERROR_NOT_FOUND
+	case errors.As(err, &limitErr):
+		return ERROR_LIMIT
+	case errors.Is(err, context.DeadlineExceeded):
+		return ERROR_TIMEOUT
+	case errors.As(err, &apiErr):
+		if apiErr.Status == http.StatusBadRequest || apiErr.Status == http.StatusUnprocessableEntity {
+			return ERROR_QUERY
+		}
+
+		if apiErr.Status >= http.StatusInternalServerError {
+			return ERROR_SERVER
+		}
+	case errors.As(err, &netErr):
+		return ERROR_NETWORK
+	}
+
+	return ERROR_OTHER
+}
+
+// a suggestion of what to do about the error, empty when there is none
+func errorHint(err error) string {
+	return ERROR_HINTS[classifyError(err)]
+}
@@ -0,0 +1,387 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	asciigraph "github.com/guptarohit/asciigraph"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+)
+
+const (
+	// at PNG_DPI, 960 by 480 pixels before the legend
+	PNG_WIDTH  = 10 * vg.Inch
+	PNG_HEIGHT = 5 * vg.Inch
+	PNG_DPI    = 96
+	PNG_MARGIN = vg.Length(12)
+)
+
+// dark like a terminal, the theme colors are picked to stand out on one
+var (
+	PNG_BACKGROUND = color.RGBA{24, 24, 24, 255}
+	PNG_GRID       = color.RGBA{58, 58, 58, 255}
+	PNG_AXIS       = color.RGBA{150, 150, 150, 255}
+	PNG_TEXT       = color.RGBA{220, 220, 220, 255}
+)
+
+// the 256 color palette asciigraph colors index into, for the terminal
+// colors the first 16 are the xterm defaults
+func ansiRGB(c asciigraph.AnsiColor) color.RGBA {
+	basic := []color.RGBA{
+		{0, 0, 0, 255}, {205, 0, 0, 255}, {0, 205, 0, 255}, {205, 205, 0, 255},
+		{0, 0, 238, 255}, {205, 0, 205, 255}, {0, 205, 205, 255}, {229, 229, 229, 255},
+		{127, 127, 127, 255}, {255, 0, 0, 255}, {0, 255, 0, 255}, {255, 255, 0, 255},
+		{92, 92, 255, 255}, {255, 0, 255, 255}, {0, 255, 255, 255}, {255, 255, 255, 255},
+	}
+
+	i := int(c)
+
+	switch {
+	case i < 16:
+		return basic[i]
+	case i < 232:
+		levels := []uint8{0, 95, 135, 175, 215, 255}
+		i -= 16
+
+		return color.RGBA{levels[i/36], levels[(i/6)%6], levels[i%6], 255}
+	default:
+		gray := uint8(8 + 10*(i-232))
+
+		return color.RGBA{gray, gray, gray, 255}
+	}
+}
+
+// the y ticks gonum/plot picks, labeled the way the op's values are shown
+func valueTicks(formatValue func(float64) string) plot.Ticker {
+	return plot.TickerFunc(func(min, max float64) []plot.Tick {
+		ticks := plot.DefaultTicks{}.Ticks(min, max)
+
+		for i, tick := range ticks {
+			if tick.Label != "" {
+				ticks[i].Label = formatValue(tick.Value)
+			}
+		}
+
+		return ticks
+	})
+}
+
+func styleAxis(axis *plot.Axis) {
+	axis.Color = PNG_AXIS
+	axis.Label.TextStyle.Color = PNG_AXIS
+	axis.Tick.Color = PNG_AXIS
+	axis.Tick.Label.Color = PNG_AXIS
+}
+
+// the runs of a series between its NaNs, which leave a gap in the line
+func seriesSegments(series []float64, times []time.Time) []plotter.XYs {
+	segments := []plotter.XYs{}
+	segment := plotter.XYs{}
+
+	for i, v := range series {
+		if math.IsNaN(v) || i >= len(times) {
+			if len(segment) > 0 {
+				segments = append(segments, segment)
+				segment = plotter.XYs{}
+			}
+
+			continue
+		}
+
+		segment = append(segment, plotter.XY{X: float64(times[i].Unix()), Y: v})
+	}
+
+	if len(segment) > 0 {
+		segments = append(segments, segment)
+	}
+
+	return segments
+}
+
+// the lines of a series in its color, with a dot for a value on its own
+func addSeries(p *plot.Plot, series []float64, times []time.Time, style draw.LineStyle) (plot.Thumbnailer, error) {
+	var thumbnail plot.Thumbnailer
+
+	for _, segment := range seriesSegments(series, times) {
+		if len(segment) == 1 {
+			scatter, err := plotter.NewScatter(segment)
+
+			if err != nil {
+				return nil, err
+			}
+
+			scatter.GlyphStyle = draw.GlyphStyle{Color: style.Color, Radius: style.Width, Shape: draw.CircleGlyph{}}
+			p.Add(scatter)
+			continue
+		}
+
+		line, err := plotter.NewLine(segment)
+
+		if err != nil {
+			return nil, err
+		}
+
+		line.LineStyle = style
+		p.Add(line)
+		thumbnail = line
+	}
+
+	if thumbnail == nil {
+		thumbnail = &plotter.Line{LineStyle: style}
+	}
+
+	return thumbnail, nil
+}
+
+// the graph plotted over the start of each interval, with the legend under
+// it one series per row. gonum/plot panics on what it can't draw, missing
+// fonts included, which is returned as an error like the rest.
+func renderGraphPNG(graph GraphData, formatValue func(float64) string, times []time.Time) (img []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	p := plot.New()
+	p.BackgroundColor = PNG_BACKGROUND
+	p.Title.Text = graph.title
+	p.Title.TextStyle.Color = PNG_TEXT
+	p.Title.Padding = PNG_MARGIN
+
+	styleAxis(&p.X)
+	styleAxis(&p.Y)
+
+	layout := "15:04"
+
+	if len(times) > 0 && times[len(times)-1].Sub(times[0]) > 24*time.Hour {
+		layout = "Jan 2 15:04"
+	}
+
+	if len(times) > 0 {
+		location := times[0].Location()
+		p.X.Label.Text = location.String()
+		p.X.Tick.Marker = plot.TimeTicks{Format: layout, Time: func(t float64) time.Time {
+			return time.Unix(int64(t), 0).In(location)
+		}}
+	}
+
+	p.Y.Tick.Marker = valueTicks(formatValue)
+
+	grid := plotter.NewGrid()
+	grid.Vertical.Color = PNG_GRID
+	grid.Horizontal.Color = PNG_GRID
+	p.Add(grid)
+
+	legend := plot.NewLegend()
+	legend.TextStyle.Color = PNG_TEXT
+	legend.Top = true
+	legend.Left = true
+	legend.Padding = PNG_MARGIN / 2
+
+	for i, series := range graph.data {
+		thumbnail, err := addSeries(p, series, times, draw.LineStyle{Color: ansiRGB(graph.colors[i]), Width: vg.Points(1.5)})
+
+		if err != nil {
+			return nil, err
+		}
+
+		legend.Add(graph.labels[i], thumbnail)
+	}
+
+	if graph.hasThreshold && len(times) > 0 {
+		line, err := plotter.NewLine(plotter.XYs{
+			{X: float64(times[0].Unix()), Y: graph.threshold},
+			{X: float64(times[len(times)-1].Unix()), Y: graph.threshold},
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		line.LineStyle = draw.LineStyle{
+			Color:  ansiRGB(graph.thresholdColor),
+			Width:  vg.Points(1),
+			Dashes: []vg.Length{vg.Points(4), vg.Points(2)},
+		}
+		p.Add(line)
+		legend.Add(fmt.Sprintf("threshold %v", formatValue(graph.threshold)), line)
+	}
+
+	legendHeight := legend.Rectangle(draw.Canvas{}).Size().Y + 2*PNG_MARGIN
+	canvas := vgimg.NewWith(vgimg.UseWH(PNG_WIDTH, PNG_HEIGHT+legendHeight), vgimg.UseDPI(PNG_DPI))
+	dc := draw.New(canvas)
+
+	dc.SetColor(PNG_BACKGROUND)
+	dc.Fill(dc.Rectangle.Path())
+
+	p.Draw(draw.Crop(dc, PNG_MARGIN, -PNG_MARGIN, legendHeight, -PNG_MARGIN))
+	legend.Draw(draw.Crop(dc, 2*PNG_MARGIN, -PNG_MARGIN, PNG_MARGIN, -(PNG_HEIGHT + PNG_MARGIN)))
+
+	var b bytes.Buffer
+
+	if _, err := (vgimg.PngCanvas{Canvas: canvas}).WriteTo(&b); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+func graphPNGPath(graph GraphData, now time.Time) string {
+	name := strings.Trim(unsafeFileChars.ReplaceAllString(graph.op, "_"), "_")
+
+	return fmt.Sprintf("graph-%v-%v.png", name, now.Format("20060102-150405"))
+}
+
+// graph-<op>-<timestamp>.png in the working directory, one per graph. Every
+// graph is rendered before any is written, and the ones written are removed
+// again when the next can't be, so it's all of them or none.
+func writeGraphPNGs(graphs []GraphData, formatValue func(string, any) string, times []time.Time, now time.Time) ([]string, error) {
+	images := [][]byte{}
+
+	for _, graph := range graphs {
+		img, err := renderGraphPNG(graph, func(v float64) string {
+			return formatValue(graph.op, v)
+		}, times)
+
+		if err != nil {
+			return nil, fmt.Errorf("can't plot %v: %w", graph.op, err)
+		}
+
+		images = append(images, img)
+	}
+
+	paths := []string{}
+
+	for i, graph := range graphs {
+		path := graphPNGPath(graph, now)
+
+		if err := os.WriteFile(path, images[i], 0o644); err != nil {
+			for _, written := range paths {
+				os.Remove(written)
+			}
+
+			return nil, err
+		}
+
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+// with the graphs saved as text instead when the images can't be, so the
+// export isn't lost
+func saveGraphPNGs(graphs []GraphData, formatValue func(string, any) string, times []time.Time, now time.Time, textPath string, text string) string {
+	paths, err := writeGraphPNGs(graphs, formatValue, times, now)
+
+	if err == nil {
+		return fmt.Sprintf("Saved %v", strings.Join(paths, ", "))
+	}
+
+	if textErr := writeGraphText(textPath, text); textErr != nil {
+		return fmt.Sprintf("Could not save the graphs: %v", err)
+	}
+
+	return fmt.Sprintf("Could not save the graphs as PNG (%v), saved them as text to %v instead", err, textPath)
+}
+
+// where each interval of the graphs starts, in the shown timezone
+func (m Model) intervalStarts() []time.Time {
+	times := []time.Time{}
+
+	for _, interval := range m.query.result.Buckets.Series {
+		times = append(times, interval.StartTime.In(m.location))
+	}
+
+	return times
+}
+
+func (m Model) ExportGraphPNG() tea.Cmd {
+	graphs := m.visibleGraphs()
+
+	if len(graphs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	textPath, text := m.graphText(now)
+	times := m.intervalStarts()
+
+	return func() tea.Msg {
+		msg := saveGraphPNGs(graphs, m.formatOpValue, times, now, textPath, text)
+
+		return Msg{
+			update: func(m *Model) {
+				m.setMsg(msg)
+			},
+		}
+	}
+}
+
+var ansiPattern = regexp.MustCompile("\x1b\\[[0-9;?]*[A-Za-z]")
+
+// the escape codes of asciigraph's and lipgloss' colors, and the padding
+// they leave at the end of lines
+func stripAnsi(s string) string {
+	lines := strings.Split(ansiPattern.ReplaceAllString(s, ""), "\n")
+
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// the graphs as they're shown, under the query and when it was saved
+func (m Model) graphText(now time.Time) (string, string) {
+	path := fmt.Sprintf("graphs-%v.txt", now.Format("20060102-150405"))
+
+	text := strings.Join([]string{
+		fmt.Sprintf("# %v", strings.ReplaceAll(m.query.apl, "\n", "\n# ")),
+		fmt.Sprintf("# %v", now.In(m.location).Format(time.RFC3339)),
+		stripAnsi(lipgloss.JoinVertical(lipgloss.Left, m.ViewGraphs(), m.ViewTimeAxis(), m.ViewLegend())),
+	}, "\n")
+
+	return path, text
+}
+
+func writeGraphText(path string, text string) error {
+	return os.WriteFile(path, []byte(strings.TrimRight(text, "\n")+"\n"), 0o644)
+}
+
+func (m Model) ExportGraphText() tea.Cmd {
+	if len(m.visibleGraphs()) == 0 {
+		return nil
+	}
+
+	path, text := m.graphText(time.Now())
+
+	return func() tea.Msg {
+		msg := fmt.Sprintf("Saved %v", path)
+
+		if err := writeGraphText(path, text); err != nil {
+			msg = fmt.Sprintf("Could not save the graphs: %v", err)
+		}
+
+		return Msg{
+			update: func(m *Model) {
+				m.setMsg(msg)
+			},
+		}
+	}
+}
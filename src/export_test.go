@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/plot/vg"
+)
+
+// antialiased, the lines only come out in their exact color along the middle
+func hasColor(img image.Image, c color.RGBA) bool {
+	near := func(a uint32, b uint8) bool {
+		return math.Abs(float64(a>>8)-float64(b)) <= 8
+	}
+
+	bounds := img.Bounds()
+
+	for x := bounds.Min.X; x < bounds.Max.X; x++ {
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+
+			if near(r, c.R) && near(g, c.G) && near(b, c.B) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func decodePNG(t *testing.T, data []byte, err error) image.Image {
+	t.Helper()
+
+	if err != nil {
+		t.Fatalf("can't render: %v", err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+
+	if err != nil {
+		t.Fatalf("not a PNG: %v", err)
+	}
+
+	return img
+}
+
+// in a working directory of its own, with nothing in it
+func inTempDir(t *testing.T) string {
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	return dir
+}
+
+func pngsIn(dir string) []string {
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.png"))
+	files := []string{}
+
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			files = append(files, filepath.Base(path))
+		}
+	}
+
+	return files
+}
+
+func TestRenderGraphPNG(t *testing.T) {
+	m := withResult(testModel(nil), "['logs']", groupedResult())
+	graph := (*m.graphs)[0]
+	graph.hasThreshold, graph.threshold, graph.thresholdColor = true, 1, m.theme.thresholdColor
+
+	formatValue := func(v float64) string {
+		return m.formatOpValue(graph.op, v)
+	}
+
+	data, err := renderGraphPNG(graph, formatValue, m.intervalStarts())
+	img := decodePNG(t, data, err)
+
+	if got := img.Bounds().Dx(); got != int(PNG_WIDTH/vg.Inch*PNG_DPI) {
+		t.Errorf("%v pixels wide, want %v", got, PNG_WIDTH/vg.Inch*PNG_DPI)
+	}
+
+	for i, group := range graph.groups {
+		if !hasColor(img, ansiRGB(graph.colors[i])) {
+			t.Errorf("no %v line", group)
+		}
+	}
+
+	if !hasColor(img, ansiRGB(graph.thresholdColor)) {
+		t.Errorf("no threshold line")
+	}
+
+	// taller as the legend gets more rows
+	for i := 0; i < 10; i++ {
+		graph.labels = append(graph.labels, graph.labels[0])
+		graph.data = append(graph.data, graph.data[0])
+		graph.colors = append(graph.colors, graph.colors[0])
+	}
+
+	data, err = renderGraphPNG(graph, formatValue, m.intervalStarts())
+
+	if taller := decodePNG(t, data, err); taller.Bounds().Dy() <= img.Bounds().Dy() {
+		t.Errorf("%v pixels high with 12 series, %v with 2", taller.Bounds().Dy(), img.Bounds().Dy())
+	}
+}
+
+// with something already where the second image goes, the first one isn't
+// left behind
+func TestPNGExportFallsBackToText(t *testing.T) {
+	dir := inTempDir(t)
+
+	m := withResult(testModel(nil), "['logs']", groupedResult())
+	graphs := m.visibleGraphs()
+	textPath, text := m.graphText(TEST_START)
+
+	os.Mkdir(graphPNGPath(graphs[1], TEST_START), 0o755)
+
+	msg := saveGraphPNGs(graphs, m.formatOpValue, m.intervalStarts(), TEST_START, textPath, text)
+
+	if !strings.Contains(msg, "saved them as text to "+textPath) {
+		t.Errorf("got %q, want it to say the graphs were saved as text", msg)
+	}
+
+	saved, err := os.ReadFile(filepath.Join(dir, textPath))
+
+	if err != nil || !strings.Contains(string(saved), "count_") {
+		t.Errorf("the graphs weren't saved as text: %v", err)
+	}
+
+	if written := pngsIn(dir); len(written) > 0 {
+		t.Errorf("left %v behind", written)
+	}
+}
+
+func TestPNGRenderFailureFallsBackToText(t *testing.T) {
+	dir := inTempDir(t)
+
+	m := withResult(testModel(nil), "['logs']", groupedResult())
+	graphs := m.visibleGraphs()
+	textPath, text := m.graphText(TEST_START)
+
+	// gonum/plot won't plot an infinity
+	graphs[1].data = [][]float64{{1, math.Inf(1)}}
+
+	msg := saveGraphPNGs(graphs, m.formatOpValue, m.intervalStarts(), TEST_START, textPath, text)
+
+	if !strings.Contains(msg, "can't plot "+graphs[1].op) || !strings.Contains(msg, "saved them as text") {
+		t.Errorf("got %q, want it to say the graph couldn't be plotted and was saved as text", msg)
+	}
+
+	if written := pngsIn(dir); len(written) > 0 {
+		t.Errorf("wrote %v", written)
+	}
+}
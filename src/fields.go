@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	axiomQuery "github.com/axiomhq/axiom-go/axiom/query"
+)
+
+// the listed fields that are present, in their order, then the rest sorted
+// unless only the listed ones are wanted
+func orderFields(data map[string]any, fields []string, fieldsOnly bool) []string {
+	ordered := []string{}
+
+	for _, field := range fields {
+		if _, ok := data[field]; ok && !stringInSlice(field, ordered) {
+			ordered = append(ordered, field)
+		}
+	}
+
+	if fieldsOnly {
+		return ordered
+	}
+
+	rest := []string{}
+
+	for field := range data {
+		if !stringInSlice(field, ordered) {
+			rest = append(rest, field)
+		}
+	}
+
+	sort.Strings(rest)
+
+	return append(ordered, rest...)
+}
+
+// every field any of the matches has, not just the first one, sorted so the
+// columns stay put across refreshes
+func matchFields(matches []axiomQuery.Entry) []string {
+	seen := map[string]bool{}
+	fields := []string{}
+
+	for _, match := range matches {
+		for field := range match.Data {
+			if !seen[field] {
+				seen[field] = true
+				fields = append(fields, field)
+			}
+		}
+	}
+
+	sort.Strings(fields)
+
+	return fields
+}
+
+// the columns after _time: exactly the chosen ones in their order, or else
+// the dataset a union match came from up front and the rest alphabetically,
+// the same on every build of the same result
+func matchColumnFields(matches []axiomQuery.Entry, chosen []string, datasetField string) []string {
+	if len(chosen) > 0 {
+		return chosen
+	}
+
+	fields := []string{}
+
+	if datasetField != "" {
+		fields = append(fields, datasetField)
+	}
+
+	for _, field := range matchFields(matches) {
+		if field != datasetField {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+// like json.MarshalIndent, but with the data fields in the given order
+// instead of the map's
+func marshalMatch(match axiomQuery.Entry, fields []string) string {
+	var b strings.Builder
+
+	writeField := func(indent string, key string, value any, last bool) {
+		keyJson, _ := json.Marshal(key)
+		valueJson, _ := json.MarshalIndent(value, indent, "  ")
+
+		b.WriteString(indent + string(keyJson) + ": " + string(valueJson))
+
+		if !last {
+			b.WriteString(",")
+		}
+
+		b.WriteString("\n")
+	}
+
+	b.WriteString("{\n")
+	writeField("  ", "_time", match.Time, false)
+	writeField("  ", "_sysTime", match.SysTime, false)
+	writeField("  ", "_rowId", match.RowID, false)
+
+	if len(fields) == 0 {
+		b.WriteString(`  "data": {}` + "\n}")
+
+		return b.String()
+	}
+
+	b.WriteString(`  "data": {` + "\n")
+
+	for i, field := range fields {
+		writeField("    ", field, match.Data[field], i == len(fields)-1)
+	}
+
+	b.WriteString("  }\n}")
+
+	return b.String()
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	axiomQuery "github.com/axiomhq/axiom-go/axiom/query"
+)
+
+func entries(data ...map[string]any) []axiomQuery.Entry {
+	matches := []axiomQuery.Entry{}
+
+	for _, d := range data {
+		matches = append(matches, axiomQuery.Entry{Time: TEST_START, Data: d})
+	}
+
+	return matches
+}
+
+// fields only some matches have still get a column, empty in the others
+func TestMatchColumnsUnion(t *testing.T) {
+	matches := entries(
+		map[string]any{"status": 200},
+		map[string]any{"status": 500, "error": "timeout"},
+		map[string]any{"user": "ana"},
+	)
+
+	want := []string{"error", "status", "user"}
+
+	if got := matchColumnFields(matches, nil, ""); !reflect.DeepEqual(got, want) {
+		t.Errorf("columns %v, want %v", got, want)
+	}
+
+	m := withResult(testModel(nil), "['logs']", &axiomQuery.Result{Matches: matches})
+	rows := m.matchesTable.Rows()
+
+	if rows[0][1] != "" || rows[1][1] != "timeout" || rows[2][2] != "" || rows[2][3] != "ana" {
+		t.Errorf("rows %q, want every field in its column, empty when missing", rows)
+	}
+}
+
+func TestMatchColumnsOrder(t *testing.T) {
+	matches := entries(
+		map[string]any{"zone": "eu", "method": "GET", "_dataset": "logs", "status": 200},
+		map[string]any{"method": "POST", "bytes": 512, "_dataset": "traces"},
+	)
+
+	tests := []struct {
+		name   string
+		chosen []string
+		want   []string
+	}{
+		{"sorted after the dataset", nil, []string{"_dataset", "bytes", "method", "status", "zone"}},
+		{"as chosen with --columns", []string{"status", "method"}, []string{"status", "method"}},
+	}
+
+	for _, test := range tests {
+		first := matchColumnFields(matches, test.chosen, getDatasetField(matches))
+
+		if !reflect.DeepEqual(first, test.want) {
+			t.Errorf("%v: columns %v, want %v", test.name, first, test.want)
+		}
+
+		// maps range in a different order every time
+		for i := 0; i < 20; i++ {
+			if got := matchColumnFields(matches, test.chosen, getDatasetField(matches)); !reflect.DeepEqual(got, first) {
+				t.Fatalf("%v: columns %v on build %v, %v before", test.name, got, i, first)
+			}
+		}
+	}
+}
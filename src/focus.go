@@ -0,0 +1,117 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	slices "golang.org/x/exp/slices"
+)
+
+const (
+	PANE_NONE = iota
+	PANE_TEXTAREA
+	PANE_TOTALS
+	PANE_MATCHES
+	PANE_DETAILS
+)
+
+// the pane keys go to, following the same priority as the key handlers
+func (m *Model) UpdateFocusedPane() {
+	switch {
+	case m.state == TYPING:
+		m.focusedPane = PANE_TEXTAREA
+	case m.state != REFRESHING || m.viewMode == "raw" || m.viewMode == "graphs":
+		m.focusedPane = PANE_NONE
+	case m.detailsFocused:
+		m.focusedPane = PANE_DETAILS
+	case m.totalsTable != nil && !m.matchesFocused:
+		m.focusedPane = PANE_TOTALS
+	case m.matchesTable != nil:
+		m.focusedPane = PANE_MATCHES
+	default:
+		m.focusedPane = PANE_NONE
+	}
+}
+
+// the panes that are there to focus, in tab order
+func (m Model) focusablePanes() []int {
+	panes := []int{}
+
+	if m.totalsTable != nil || (m.showIntervals && m.intervalsTable != nil) {
+		panes = append(panes, PANE_TOTALS)
+	}
+
+	if m.matchesTable != nil {
+		panes = append(panes, PANE_MATCHES)
+
+		if m.matchesTableHighlightedIdx != -1 && m.matchDetailsExpanded {
+			panes = append(panes, PANE_DETAILS)
+		}
+	}
+
+	return panes
+}
+
+// tab moves forward through the panes and shift+tab back, wrapping around
+func (m *Model) CycleFocus(step int) tea.Cmd {
+	panes := m.focusablePanes()
+
+	if len(panes) == 0 {
+		return nil
+	}
+
+	idx := slices.Index(panes, m.focusedPane)
+	next := panes[(idx+step+len(panes))%len(panes)]
+
+	// with nothing focused yet, shift+tab starts from the end
+	if idx == -1 && step < 0 {
+		next = panes[len(panes)-1]
+	}
+
+	return m.FocusPane(next)
+}
+
+func (m *Model) FocusPane(pane int) tea.Cmd {
+	m.detailsFocused = pane == PANE_DETAILS
+	m.matchesFocused = pane != PANE_TOTALS
+	m.focusedPane = pane
+
+	switch pane {
+	case PANE_TOTALS:
+		// in place of the totals, the intervals table only scrolls
+		if m.showIntervals && m.intervalsTable != nil {
+			m.intervalsTable.Focus()
+
+			return nil
+		}
+
+		m.totalsTable.Focus()
+
+		return m.HighlightRow(m.totalsTable.SelectedRow())
+	case PANE_MATCHES:
+		if m.totalsTable != nil {
+			m.totalsTable.Blur()
+		}
+
+		if m.intervalsTable != nil {
+			m.intervalsTable.Blur()
+		}
+
+		if m.matchesTableHighlightedIdx == -1 {
+			m.UpdateMatchesHighlight()
+		}
+	}
+
+	return nil
+}
+
+// every pane has a border so focus moving around doesn't shift the layout,
+// only the focused one's is visible
+func (m Model) paneStyle(pane int) lipgloss.Style {
+	if pane == m.focusedPane {
+		return tableStyle.Copy().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(m.theme.highlightBackground)
+	}
+
+	return tableStyle.Copy().Border(lipgloss.HiddenBorder())
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+type UnitRule struct {
+	// substrings of the op name, e.g. avg(duration)
+	match  []string
+	format func(v float64, numbers NumberFormat) string
+}
+
+var UNIT_RULES = []UnitRule{
+	{match: []string{"duration", "latency", "elapsed"}, format: formatNanoseconds},
+	{match: []string{"bytes", "size"}, format: formatBytes},
+}
+
+func unitRule(opName string) *UnitRule {
+	name := strings.ToLower(opName)
+
+	for i, rule := range UNIT_RULES {
+		for _, match := range rule.match {
+			if strings.Contains(name, match) {
+				return &UNIT_RULES[i]
+			}
+		}
+	}
+
+	return nil
+}
+
+// humane formatting for known units, plain otherwise
+func formatValue(opName string, v float64, numbers NumberFormat) string {
+	if rule := unitRule(opName); rule != nil {
+		return rule.format(v, numbers)
+	}
+
+	return numbers.Number(v)
+}
+
+// 12345678 -> 12,345,678, only for values without a fractional part
+func groupDigits(v float64) (string, bool) {
+	if v != math.Trunc(v) || math.Abs(v) >= 1e15 {
+		return "", false
+	}
+
+	digits := fmt.Sprintf("%.0f", math.Abs(v))
+
+	var b strings.Builder
+
+	if v < 0 {
+		b.WriteByte('-')
+	}
+
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+
+		b.WriteRune(digit)
+	}
+
+	return b.String(), true
+}
+
+func formatNanoseconds(v float64, numbers NumberFormat) string {
+	units := []struct {
+		name string
+		size float64
+	}{
+		{"d", 24 * 60 * 60 * 1e9},
+		{"h", 60 * 60 * 1e9},
+		{"m", 60 * 1e9},
+		{"s", 1e9},
+		{"ms", 1e6},
+		{"µs", 1e3},
+	}
+
+	for _, unit := range units {
+		if math.Abs(v) >= unit.size {
+			return numbers.Fixed(v/unit.size, 1) + unit.name
+		}
+	}
+
+	return numbers.Fixed(v, 0) + "ns"
+}
+
+func formatBytes(v float64, numbers NumberFormat) string {
+	units := []string{"B", "KB", "MB", "GB", "TB", "PB"}
+
+	idx := 0
+
+	for math.Abs(v) >= 1000 && idx < len(units)-1 {
+		v /= 1000
+		idx += 1
+	}
+
+	if idx == 0 {
+		return numbers.Fixed(v, 0) + units[idx]
+	}
+
+	return numbers.Fixed(v, 1) + units[idx]
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	axiomQuery "github.com/axiomhq/axiom-go/axiom/query"
+	"github.com/charmbracelet/bubbles/table"
+)
+
+// the exact numbers behind the graphs, one row per interval and a column for
+// every group and op
+func (m *Model) UpdateIntervalsTable(result *axiomQuery.Result) {
+	if result == nil || len(result.Buckets.Series) == 0 || m.queryMeta == nil {
+		m.intervalsTable = nil
+		m.showIntervals = false
+		return
+	}
+
+	columns := []table.Column{{Title: "_time", Width: len("_time")}}
+
+	type seriesColumn struct {
+		group string
+		op    string
+	}
+
+	seriesColumns := []seriesColumn{}
+
+	for _, group := range m.queryMeta.groups {
+		for _, op := range m.queryMeta.ops {
+			title := op.name
+
+			// without a group by the key is empty
+			if group != "" {
+				title = fmt.Sprintf("%v · %v", group, op.name)
+			}
+
+			columns = append(columns, table.Column{Title: title, Width: minInt(maxInt(len(title), 12), 30)})
+			seriesColumns = append(seriesColumns, seriesColumn{group: group, op: op.name})
+		}
+	}
+
+	rows := make([]table.Row, len(result.Buckets.Series))
+
+	for i, interval := range result.Buckets.Series {
+		rows[i] = table.Row{m.formatTime(interval.StartTime)}
+		columns[0].Width = maxInt(columns[0].Width, len(rows[i][0]))
+	}
+
+	for _, column := range seriesColumns {
+		for i, v := range groupSeries(result, m.queryMeta.orderedGroupKeys, column.group, column.op) {
+			value := ""
+
+			if !math.IsNaN(v) {
+				value = m.formatOpValue(column.op, v)
+			}
+
+			rows[i] = append(rows[i], value)
+		}
+	}
+
+	t := table.New(
+		table.WithColumns(columns),
+		table.WithRows(rows),
+		table.WithKeyMap(initTableKeyMap()),
+	)
+
+	s := table.DefaultStyles()
+	s.Selected = s.Selected.Foreground(m.theme.highlightForeground).
+		Background(m.theme.highlightBackground).
+		Bold(false)
+	t.SetStyles(s)
+
+	if m.focusedPane == PANE_TOTALS {
+		t.Focus()
+	} else {
+		t.Blur()
+	}
+
+	m.intervalsTable = &t
+}
+
+// the intervals table takes the place of the totals
+func (m *Model) ToggleIntervals() {
+	if m.intervalsTable == nil {
+		m.setMsg("No intervals in this result")
+		return
+	}
+
+	m.showIntervals = !m.showIntervals
+
+	if m.showIntervals {
+		m.setMsg("Showing the value of every interval")
+	} else {
+		m.setMsg("Showing the totals")
+	}
+}
+
+func (m Model) ViewIntervals() string {
+	return m.paneStyle(PANE_TOTALS).Render(m.intervalsTable.View())
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// colorize already marshaled (and valid) JSON token by token
+func colorizeJson(str string, colors SyntaxColors) string {
+	// the profile is forced to ascii when colors are disabled
+	if lipgloss.ColorProfile() == termenv.Ascii {
+		return str
+	}
+
+	keyStyle := lipgloss.NewStyle().Foreground(colors.key)
+	stringStyle := lipgloss.NewStyle().Foreground(colors.str)
+	numberStyle := lipgloss.NewStyle().Foreground(colors.number)
+	boolStyle := lipgloss.NewStyle().Foreground(colors.boolean)
+	nullStyle := lipgloss.NewStyle().Foreground(colors.null)
+
+	var b strings.Builder
+
+	for i := 0; i < len(str); {
+		c := str[i]
+
+		switch {
+		case c == '"':
+			end := i + 1
+			for end < len(str) && str[end] != '"' {
+				if str[end] == '\\' {
+					end += 1
+				}
+				end += 1
+			}
+			end += 1
+
+			token := str[i:end]
+
+			// a string followed by a colon is an object key
+			rest := strings.TrimLeft(str[end:], " \n\t")
+
+			if strings.HasPrefix(rest, ":") {
+				b.WriteString(keyStyle.Render(token))
+			} else {
+				b.WriteString(stringStyle.Render(token))
+			}
+
+			i = end
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := i
+			for end < len(str) && strings.IndexByte("+-.eE0123456789", str[end]) != -1 {
+				end += 1
+			}
+
+			b.WriteString(numberStyle.Render(str[i:end]))
+			i = end
+		case strings.HasPrefix(str[i:], "true"):
+			b.WriteString(boolStyle.Render("true"))
+			i += 4
+		case strings.HasPrefix(str[i:], "false"):
+			b.WriteString(boolStyle.Render("false"))
+			i += 5
+		case strings.HasPrefix(str[i:], "null"):
+			b.WriteString(nullStyle.Render("null"))
+			i += 4
+		default:
+			b.WriteByte(c)
+			i += 1
+		}
+	}
+
+	return b.String()
+}
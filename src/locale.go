@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// numbers print the way they always have in the C locale, so output stays
+// the same for scripts unless a locale is asked for
+const DEFAULT_LOCALE = "C"
+
+// read from the environment like the C library does, the first one set wins
+var LOCALE_ENV = []string{"LC_ALL", "LC_NUMERIC", "LANG"}
+
+// everything numeric that's shown goes through here
+type NumberFormat struct {
+	// nil in the C locale
+	printer *message.Printer
+	group   bool
+}
+
+// POSIX names like de_DE.UTF-8 are taken as well as language tags like de-DE
+func parseLocale(name string) (*message.Printer, error) {
+	if name == "auto" {
+		name = DEFAULT_LOCALE
+
+		for _, env := range LOCALE_ENV {
+			if value := os.Getenv(env); value != "" {
+				name = value
+				break
+			}
+		}
+	}
+
+	name, _, _ = strings.Cut(name, ".")
+	name, _, _ = strings.Cut(name, "@")
+
+	if name == "C" || name == "POSIX" {
+		return nil, nil
+	}
+
+	tag, err := language.Parse(strings.ReplaceAll(name, "_", "-"))
+
+	if err != nil {
+		return nil, fmt.Errorf("unknown locale %q, expected C, auto or a language tag like de or fr-CH", name)
+	}
+
+	return message.NewPrinter(tag), nil
+}
+
+// v in full, with thousands separators unless they're turned off. In the C
+// locale only whole numbers are grouped.
+func (f NumberFormat) Number(v float64) string {
+	if f.printer == nil {
+		if f.group {
+			if str, ok := groupDigits(v); ok {
+				return str
+			}
+		}
+
+		return fmt.Sprintf("%v", v)
+	}
+
+	// %v through the printer would turn large numbers scientific
+	options := []number.Option{number.MaxFractionDigits(12)}
+
+	if !f.group {
+		options = append(options, number.NoSeparator())
+	}
+
+	return f.printer.Sprint(number.Decimal(v, options...))
+}
+
+// v rounded to a fixed number of decimals and never grouped, for values
+// shown with a unit
+func (f NumberFormat) Fixed(v float64, decimals int) string {
+	str := fmt.Sprintf("%.*f", decimals, v)
+
+	return strings.Replace(str, ".", f.DecimalSeparator(), 1)
+}
+
+func (f NumberFormat) DecimalSeparator() string {
+	if f.printer == nil {
+		return "."
+	}
+
+	return strings.Trim(f.printer.Sprint(number.Decimal(1.5, number.NoSeparator())), "15")
+}
+
+// asciigraph prints its own y axis labels with a ".", swapped in place so
+// the labels keep their width
+func (f NumberFormat) LocalizeAxis(plot string) string {
+	separator := f.DecimalSeparator()
+
+	if separator == "." {
+		return plot
+	}
+
+	lines := strings.Split(plot, "\n")
+
+	for i, line := range lines {
+		axis := strings.IndexAny(line, "┤┼")
+
+		if axis == -1 {
+			continue
+		}
+
+		lines[i] = strings.ReplaceAll(line[:axis], ".", separator) + line[axis:]
+	}
+
+	return strings.Join(lines, "\n")
+}
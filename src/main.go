@@ -16,7 +16,13 @@ func main() {
 	// }
 	// defer f.Close()
 
-	m := initialModel()
+	config := parseConfig()
+
+	if config.query != "" {
+		os.Exit(runOnce(config))
+	}
+
+	m := initialModel(config)
 
 	p := tea.NewProgram(m, tea.WithAltScreen())
 
@@ -6,21 +6,24 @@ import (
 	"fmt"
 	"hash/fnv"
 	"math"
-	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
-	axiom "github.com/axiomhq/axiom-go/axiom"
 	axiomQuery "github.com/axiomhq/axiom-go/axiom/query"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/spinner"
 	table "github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/bubbles/textarea"
-	"github.com/charmbracelet/bubbles/timer"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	asciigraph "github.com/guptarohit/asciigraph"
+	"github.com/muesli/termenv"
 	slices "golang.org/x/exp/slices"
 )
 
@@ -30,48 +33,111 @@ const (
 	REFRESHING
 )
 
-var COLORS = []asciigraph.AnsiColor{
-	asciigraph.Blue,
-	asciigraph.Magenta,
-	asciigraph.Cyan,
-	asciigraph.Green,
-	asciigraph.Yellow,
-	asciigraph.Red,
-	asciigraph.AliceBlue,
-	asciigraph.Cornsilk,
-	asciigraph.Crimson,
-	asciigraph.DarkViolet,
-	asciigraph.DeepPink,
-	asciigraph.Gold,
-	asciigraph.Indigo,
-	asciigraph.Lavender,
-	asciigraph.LightCoral,
-	asciigraph.LightSalmon,
-}
-
-var PULSE_STEP_COLORS = []string{
-	"#432155",
-	"#4e2667",
-	"#5f2d84",
-	"#7938b2",
-	"#8e4ec6",
-	"#9d5bd2",
-	"#8e4ec6",
-	"#7938b2",
-	"#5f2d84",
-	"#4e2667",
+// seconds between a result coming in and the next refresh, f cycles
+// through the presets
+const (
+	DEFAULT_REFRESH_INTERVAL = 5
+	MIN_REFRESH_INTERVAL     = 2
+)
+
+var REFRESH_INTERVALS = []int{2, 5, 10, 30, 60}
+
+type KeyBinding struct {
+	group string
+	keys  string
+	help  string
 }
 
+// keep in sync with the key handlers in Update
+var KEY_BINDINGS = []KeyBinding{
+	{group: "global", keys: "ctrl+c", help: "quit"},
+	{group: "global", keys: "f1", help: "toggle help"},
+	{group: "typing", keys: "ctrl+s/alt+enter", help: "run query"},
+	{group: "typing", keys: "enter", help: "new line, keeping the indentation"},
+	{group: "typing", keys: "tab", help: "complete APL keyword or dataset, again to cycle"},
+	{group: "typing", keys: "alt+1 … alt+5", help: "set time range (5m, 15m, 1h, 24h, 7d)"},
+	{group: "typing", keys: "ctrl+l", help: "clear the query and its result"},
+	{group: "typing", keys: "ctrl+y", help: "copy the query to the clipboard"},
+	{group: "typing", keys: "ctrl+b", help: "bookmark the query"},
+	{group: "typing", keys: "ctrl+o", help: "open bookmarks"},
+	{group: "querying", keys: "esc", help: "cancel query"},
+	{group: "querying", keys: "?", help: "toggle help"},
+	{group: "refreshing", keys: "esc", help: "edit query"},
+	{group: "refreshing", keys: "a", help: "edit the query behind the results, over what was typed since"},
+	{group: "refreshing", keys: "↑/k ↓/j", help: "move selection"},
+	{group: "refreshing", keys: "g/home G/end", help: "go to start / end"},
+	{group: "refreshing", keys: "ctrl+u ctrl+d", help: "half page up / down"},
+	{group: "refreshing", keys: "pgup pgdn", help: "page up / down"},
+	{group: "refreshing", keys: "r", help: "re-run the query now"},
+	{group: "refreshing", keys: "t", help: "graph all groups or the top 3, 5, 10"},
+	{group: "refreshing", keys: "v", help: "cycle view: all, graphs, tables, raw"},
+	{group: "refreshing", keys: "V", help: "toggle the raw result (scroll with the table keys)"},
+	{group: "refreshing", keys: "p", help: "cycle graph precision: auto, 0-4"},
+	{group: "refreshing", keys: "+ -", help: "taller / shorter graphs"},
+	{group: "refreshing", keys: "f", help: "cycle the refresh interval: 2s, 5s, 10s, 30s, 1m"},
+	{group: "refreshing", keys: "T", help: "cycle the color theme"},
+	{group: "refreshing", keys: "z", help: "show times in UTC / the local timezone"},
+	{group: "refreshing", keys: "space", help: "in the totals: select / unselect the group to compare"},
+	{group: "refreshing", keys: "c", help: "clear the highlighted and selected groups"},
+	{group: "refreshing", keys: "1 … 9", help: "show / hide the graph of the nth op"},
+	{group: "refreshing", keys: "i", help: "toggle the totals and the value of every interval"},
+	{group: "refreshing", keys: "b", help: "compare with the window before, dimmed"},
+	{group: "refreshing", keys: "o", help: "overlay the shown ops on one graph, when they share a unit and scale"},
+	{group: "refreshing", keys: "e", help: "save the graphs as text"},
+	{group: "refreshing", keys: "E", help: "save the graphs as PNG images"},
+	{group: "refreshing", keys: "m", help: "load older matches, when the result has more"},
+	{group: "refreshing", keys: "←/h →/l", help: "in the matches: scroll the columns, _time stays"},
+	{group: "refreshing", keys: "x", help: "expand / collapse match details"},
+	{group: "refreshing", keys: "w", help: "open the query in the Axiom web app"},
+	{group: "refreshing", keys: "tab shift+tab", help: "move between totals, matches and their details"},
+	{group: "refreshing", keys: "/ n N", help: "in the details: search, next and previous occurrence"},
+	{group: "refreshing", keys: "/", help: "filter matches (enter keeps, esc clears)"},
+	{group: "refreshing", keys: "s", help: "cycle totals sort column"},
+	{group: "refreshing", keys: "S", help: "toggle totals sort order"},
+	{group: "refreshing", keys: "?", help: "toggle help"},
+}
+
+// shown first in the collapsed match details when present
+var SUMMARY_MATCH_FIELDS = []string{"message", "msg", "level", "severity", "service.name", "name", "status"}
+
+// fields that identify the dataset a match came from, e.g. union withsource=
+var DATASET_FIELDS = []string{"_source", "$source", "source_dataset", "dataset", "_dataset"}
+
+// the long tail of groups outside of the top N is graphed as one series
+const OTHER_GROUP = "other"
+
+const NO_RESULTS_MSG = "No results for the given time range."
+
+var VIEW_MODES = []string{"all", "graphs", "tables", "raw"}
+
 var tableStyle = lipgloss.NewStyle().Padding(1)
 
+const (
+	DEFAULT_GRAPH_WIDTH  = 50
+	DEFAULT_GRAPH_HEIGHT = 10
+	MIN_GRAPH_WIDTH      = 30
+	MIN_GRAPH_HEIGHT     = 3
+	// the height follows the window until it's set
+	AUTO_GRAPH_HEIGHT = 0
+	// room left for the y axis labels, the plot is narrowed further when
+	// they turn out wider
+	GRAPH_LABEL_WIDTH = 15
+	// y axis labels plus the border around each graph
+	GRAPH_CELL_OVERHEAD = GRAPH_LABEL_WIDTH + 2
+	// before the window size is known, and the least it's shrunk to after
+	DEFAULT_MATCHES_HEIGHT = 20
+	MIN_MATCHES_HEIGHT     = 5
+)
+
 type errMsg error
 
 type Model struct {
 	ready                      bool
 	textarea                   textarea.Model
 	spinner                    spinner.Model
+	spinnerType                string
 	state                      int
-	client                     *axiom.Client
+	client                     Querier
 	query                      *Query
 	msg                        string
 	matchesTable               *table.Model
@@ -80,9 +146,110 @@ type Model struct {
 	queryMeta                  *QueryMeta
 	otherMsg                   string
 	totalsTable                *table.Model
+	intervalsTable             *table.Model
+	showIntervals              bool
 	highlightedGroup           string
-	refreshTimeout             int
-	pulseStep                  int
+	selectedGroups             map[string]bool
+	// the shown ops on one graph, see visibleGraphs
+	overlayOps bool
+	// the same-length window before the query's, see ToggleCompare
+	compare      bool
+	previous     *axiomQuery.Result
+	previousSpan string
+	hiddenOps    map[string]bool
+	connection   string
+	// terminals that don't report focus never blur, so refreshing goes on
+	focused            bool
+	refreshPaused      bool
+	lastResultAt       time.Time
+	refreshTimeout     int
+	refreshInterval    int
+	refreshProgress    progress.Model
+	pulseStep          int
+	completions        []string
+	completionInserted string
+	completionIdx      int
+	datasets           []string
+	datasetsLoading    bool
+	splashClosing      bool
+	queryGen           int
+	cancelQuery        context.CancelFunc
+	queryCtx           context.Context
+	// as typed, see EditLastQuery
+	lastRunApl string
+	// where the next page of matches starts, empty when there's none
+	moreCursor   string
+	loadingMore  bool
+	cancelPage   context.CancelFunc
+	retries      int
+	retryDelay   time.Duration
+	retryAttempt int
+	recordDir    string
+	vars         map[string]string
+	// per op, see --threshold and CheckThresholds
+	thresholds       map[string]float64
+	bell             bool
+	wasOver          map[string]bool
+	flashing         map[string]bool
+	flashGen         int
+	bellRinging      bool
+	matchLimit       int
+	limitWarning     string
+	tail             bool
+	matchAges        rowAges
+	totalAges        rowAges
+	detailFields     []string
+	detailFieldsOnly bool
+	matchColumns     []string
+	watchFile        string
+	watchModTime     time.Time
+	watchMissing     bool
+	focusedPane      int
+	matchesFocused   bool
+	// the first matches column shown after _time, see visibleMatchColumns.
+	// The table keeps its columns to itself.
+	matchColumnOffset    int
+	matchesColumns       []table.Column
+	autoRefresh          bool
+	showHelp             bool
+	theme                Theme
+	sortCol              int
+	sortAsc              bool
+	matches              []axiomQuery.Entry
+	matchesFilter        string
+	filterInput          textinput.Model
+	filtering            bool
+	bookmarks            map[string]string
+	bookmarkMode         int
+	bookmarkInput        textinput.Model
+	bookmarkIdx          int
+	queryWarning         string
+	viewMode             string
+	width                int
+	height               int
+	precision            int
+	fixedGraphHeight     int
+	prefs                Prefs
+	prefsGen             int
+	prefsUnsaved         bool
+	location             *time.Location
+	timeFormat           string
+	matchDetailsExpanded bool
+	noColor              bool
+	rawViewport          viewport.Model
+	detailsViewport      viewport.Model
+	detailsFocused       bool
+	detailsJson          string
+	detailSearching      bool
+	detailSearchInput    textinput.Model
+	detailSearch         string
+	detailSearchIdx      int
+	detailSearchLines    []int
+	refreshGen           int
+	queryInFlight        bool
+	units                bool
+	numbers              NumberFormat
+	topN                 int
 }
 
 type Query struct {
@@ -102,12 +269,24 @@ type QueryMeta struct {
 	groups           []string
 	ops              []Op
 	groupColors      map[string]asciigraph.AnsiColor
+	rankedGroups     []string
 }
 
 type GraphData struct {
 	title  string
+	op     string
+	groups []string
+	// what each series is shown as, its group unless there's more to it
+	labels []string
 	data   [][]float64
 	colors []asciigraph.AnsiColor
+	// groups without a single number for the op, left out of the plot
+	noData []string
+	// from --threshold, with the groups over it in their newest interval
+	threshold      float64
+	hasThreshold   bool
+	thresholdColor asciigraph.AnsiColor
+	over           []string
 }
 
 // weird general message
@@ -116,65 +295,419 @@ type Msg struct {
 }
 
 type ResultMsg struct {
-	apl    string
-	result *axiomQuery.Result
-	err    error
+	apl       string
+	result    *axiomQuery.Result
+	err       error
+	gen       int
+	recordErr error
+	// set when only matches after it were queried, see MergeTail
+	since time.Time
+	// the same-length window before, while comparing
+	previous    *axiomQuery.Result
+	previousErr error
 }
 
-type RefreshMsg timer.TickMsg
-type ReRunMsg struct{}
+// stamped with the countdown they belong to so only one countdown runs
+type RefreshMsg struct {
+	gen int
+}
+type ReRunMsg struct {
+	gen int
+}
 type PulseMsg struct{}
 
-func initSpinner() spinner.Model {
+// arrows plus vim style keys, leaving single letters free for other bindings
+func initTableKeyMap() table.KeyMap {
+	return table.KeyMap{
+		LineUp: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑/k", "up"),
+		),
+		LineDown: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓/j", "down"),
+		),
+		PageUp: key.NewBinding(
+			key.WithKeys("pgup"),
+			key.WithHelp("pgup", "page up"),
+		),
+		PageDown: key.NewBinding(
+			key.WithKeys("pgdown"),
+			key.WithHelp("pgdn", "page down"),
+		),
+		HalfPageUp: key.NewBinding(
+			key.WithKeys("ctrl+u"),
+			key.WithHelp("ctrl+u", "½ page up"),
+		),
+		HalfPageDown: key.NewBinding(
+			key.WithKeys("ctrl+d"),
+			key.WithHelp("ctrl+d", "½ page down"),
+		),
+		GotoTop: key.NewBinding(
+			key.WithKeys("home", "g"),
+			key.WithHelp("g/home", "go to start"),
+		),
+		GotoBottom: key.NewBinding(
+			key.WithKeys("end", "G"),
+			key.WithHelp("G/end", "go to end"),
+		),
+	}
+}
+
+func initFilterInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = "/"
+	ti.Placeholder = "filter matches..."
+	return ti
+}
+
+// same keys as the tables, leaving single letters free for other bindings
+func initViewport() viewport.Model {
+	vp := viewport.New(80, 20)
+
+	vp.KeyMap = viewport.KeyMap{
+		Up: key.NewBinding(
+			key.WithKeys("up", "k"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down", "j"),
+		),
+		PageUp: key.NewBinding(
+			key.WithKeys("pgup"),
+		),
+		PageDown: key.NewBinding(
+			key.WithKeys("pgdown"),
+		),
+		HalfPageUp: key.NewBinding(
+			key.WithKeys("ctrl+u"),
+		),
+		HalfPageDown: key.NewBinding(
+			key.WithKeys("ctrl+d"),
+		),
+	}
+
+	return vp
+}
+
+var SPINNERS = map[string]spinner.Spinner{
+	"line":      spinner.Line,
+	"dot":       spinner.Dot,
+	"minidot":   spinner.MiniDot,
+	"jump":      spinner.Jump,
+	"pulse":     spinner.Pulse,
+	"points":    spinner.Points,
+	"globe":     spinner.Globe,
+	"moon":      spinner.Moon,
+	"monkey":    spinner.Monkey,
+	"meter":     spinner.Meter,
+	"hamburger": spinner.Hamburger,
+}
+
+const DEFAULT_SPINNER = "dot"
+
+func spinnerNames() []string {
+	names := make([]string, 0, len(SPINNERS))
+
+	for name := range SPINNERS {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func initRefreshProgress(theme Theme, noColor bool) progress.Model {
+	opts := []progress.Option{
+		progress.WithWidth(20),
+		progress.WithoutPercentage(),
+		progress.WithSolidFill(string(theme.highlightBackground)),
+	}
+
+	if noColor {
+		opts = append(opts, progress.WithColorProfile(termenv.Ascii))
+	}
+
+	return progress.New(opts...)
+}
+
+func initSpinner(spinnerType string) spinner.Model {
 	spin := spinner.New()
-	spin.Spinner = spinner.Dot
+	spin.Spinner = SPINNERS[DEFAULT_SPINNER]
+
+	if preset, ok := SPINNERS[spinnerType]; ok {
+		spin.Spinner = preset
+	}
+
 	return spin
 }
 
-func initialModel() Model {
+func initialModel(config Config) Model {
+	return newModel(config, newQuerier(config))
+}
+
+// everything but the client, so a model can be put together without
+// credentials, e.g. to render a canned result
+func newModel(config Config, client Querier) Model {
 	ti := textarea.New()
 	ti.SetWidth(100)
 
-	ti.Placeholder = "Enter an APL query..."
+	ti.Placeholder = "Enter an APL query, ctrl+s to run..."
 	ti.Focus()
 
-	client, err := axiom.NewClient(
-	// axiom.SetPersonalTokenConfig("AXIOM_TOKEN", "AXIOM_ORG_ID"),
-	// axiom.SetURL("AXIOM_URL"),
-	)
+	if config.noColor {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+
+	msg := config.warning
+	bookmarks, err := loadBookmarks()
 
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		msg = fmt.Sprintf("Could not load bookmarks: %v", err)
 	}
 
 	return Model{
-		textarea: ti,
-		spinner:  initSpinner(),
-		state:    TYPING,
-		client:   client,
+		ready:             config.noSplash,
+		textarea:          ti,
+		spinner:           initSpinner(config.spinnerType),
+		refreshProgress:   initRefreshProgress(config.theme, config.noColor),
+		rawViewport:       initViewport(),
+		detailsViewport:   initViewport(),
+		filterInput:       initFilterInput(),
+		detailSearchInput: initDetailSearchInput(),
+		msg:               msg,
+		bookmarks:         bookmarks,
+		bookmarkInput:     initBookmarkInput(),
+		state:             TYPING,
+		client:            client,
+		connection:        connectionOf(client),
+		focused:           true,
 		query: &Query{
 			apl: "",
 		},
-		pulseStep: 9,
+		pulseStep:        len(config.theme.pulseColors) - 1,
+		theme:            config.theme,
+		viewMode:         config.viewMode,
+		prefs:            config.prefs,
+		precision:        config.precision,
+		refreshInterval:  config.refreshInterval,
+		fixedGraphHeight: config.graphHeight,
+		location:         config.location,
+		timeFormat:       config.timeFormat,
+		noColor:          config.noColor,
+		units:            config.units,
+		numbers:          config.numbers,
+		topN:             config.topN,
+		spinnerType:      config.spinnerType,
+		retries:          config.retries,
+		retryDelay:       config.retryDelay,
+		recordDir:        config.recordDir,
+		vars:             config.vars,
+		thresholds:       config.thresholds,
+		bell:             config.bell,
+		matchLimit:       config.matchLimit,
+		tail:             config.tail,
+		detailFields:     config.detailFields,
+		detailFieldsOnly: config.detailFieldsOnly,
+		matchColumns:     config.matchColumns,
+		watchFile:        config.watch,
+		selectedGroups:   map[string]bool{},
+		hiddenOps:        map[string]bool{},
+		sortCol:          -1,
+		sortAsc:          true,
 	}
 }
 
 func (m *Model) RunQuery(apl string) tea.Cmd {
+	m.lastRunApl = apl
+
+	return m.startQuery(apl, time.Time{})
+}
+
+// back to typing the query behind the results, over whatever was typed
+// since. With placeholders it's the query as typed, before they were filled.
+func (m *Model) EditLastQuery() tea.Cmd {
+	apl := m.query.apl
+
+	if typed, err := substituteVars(m.lastRunApl, m.vars); err == nil && typed == apl && m.lastRunApl != "" {
+		apl = m.lastRunApl
+	}
+
+	if apl == "" {
+		return nil
+	}
+
+	m.CloseCompletions()
+	m.textarea.SetValue(apl) // leaves the cursor at the end
+	m.textarea.Focus()
+	m.setState(TYPING)
+
+	return textarea.Blink
+}
+
+// with since set only matches after it are queried, for tailing
+func (m *Model) startQuery(apl string, since time.Time) tea.Cmd {
+	apl, err := substituteVars(apl, m.vars)
+
+	if err != nil {
+		m.setMsg(fmt.Sprintf("Can't run the query: %v", err))
+
+		return nil
+	}
+
 	m.setMsg("Running query...")
 	m.setState(QUERYING)
+	m.autoRefresh = false
+	m.queryInFlight = true
+
+	// results are stamped with the generation so canceled or overlapping
+	// queries that resolve late can be ignored
+	m.queryGen += 1
+	gen := m.queryGen
+
+	// a newer query supersedes whatever is still in flight
+	if m.cancelQuery != nil {
+		m.cancelQuery()
+	}
+
+	m.CancelLoadMore()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelQuery = cancel
+	m.queryCtx = ctx
+	m.retryAttempt = 0
+
+	// tick from the model so ticks of previous runs are dropped by the spinner
+	return tea.Batch(m.spinner.Tick, m.runQuery(ctx, apl, since, gen, 0))
+}
+
+// waits out the delay first, unless the query is canceled in the meantime
+func (m *Model) runQuery(ctx context.Context, apl string, since time.Time, gen int, delay time.Duration) tea.Cmd {
+	sent := apl
+
+	if !since.IsZero() {
+		sent = tailQuery(apl, since)
+	}
+
+	// a tail refresh keeps the previous window it has
+	compare := m.compare && since.IsZero()
+
+	return func() tea.Msg {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ResultMsg{apl: apl, err: ctx.Err(), gen: gen, since: since}
+		}
+
+		res, err := m.client.Query(ctx, sent)
+
+		var previous *axiomQuery.Result
+		var previousErr error
+
+		if compare && err == nil {
+			if previousApl, _, ok := previousWindow(apl); ok {
+				previous, previousErr = m.client.Query(ctx, previousApl)
+			} else {
+				previousErr = errNoTimeRange
+			}
+		}
 
-	return tea.Batch(spinner.Tick, func() tea.Msg {
+		// written here so a slow disk doesn't hold up the UI
+		var recordErr error
 
-		ctx := context.Background()
-		res, err := m.client.Query(ctx, apl)
+		if m.recordDir != "" && err == nil {
+			recordErr = recordResult(m.recordDir, res)
+		}
 
 		return ResultMsg{
-			apl:    apl,
-			result: res,
-			err:    err,
+			apl:         apl,
+			result:      res,
+			err:         err,
+			gen:         gen,
+			recordErr:   recordErr,
+			since:       since,
+			previous:    previous,
+			previousErr: previousErr,
 		}
-	})
+	}
+}
+
+// server errors and network blips are retried with an exponential backoff,
+// anything else is shown right away
+func (m *Model) RetryQuery(msg ResultMsg) (tea.Cmd, bool) {
+	kind := classifyError(msg.err)
+
+	if m.retryAttempt >= m.retries || (kind != ERROR_SERVER && kind != ERROR_NETWORK) {
+		return nil, false
+	}
+
+	m.retryAttempt += 1
+	m.setMsg(fmt.Sprintf("Retrying %v/%v…", m.retryAttempt, m.retries))
+
+	delay := m.retryDelay * time.Duration(1<<(m.retryAttempt-1))
+
+	return m.runQuery(m.queryCtx, msg.apl, msg.since, msg.gen, delay), true
+}
+
+func (m *Model) RefreshQuery() tea.Cmd {
+	var since time.Time
+
+	if m.tail && m.query.result != nil {
+		since = newestMatchTime(m.query.result.Matches)
+	}
+
+	cmd := m.startQuery(m.query.apl, since)
+
+	m.setMsg("Refreshing...")
+	m.autoRefresh = true
+
+	return cmd
+}
+
+// back to an empty editor, dropping the last result along with the query
+func (m *Model) ClearQuery() {
+	m.textarea.Reset()
+	m.CloseCompletions()
+
+	m.query = &Query{apl: ""}
+	m.lastRunApl = ""
+	m.moreCursor = ""
+	m.matchColumnOffset = 0
+	m.queryWarning = ""
+	m.limitWarning = ""
+	m.matchesFilter = ""
+	m.highlightedGroup = ""
+	m.selectedGroups = map[string]bool{}
+	m.previous = nil
+	m.previousSpan = ""
+	m.setMsg("")
+
+	m.UpdateQueryMeta(nil)
+	m.UpdateTotals(nil)
+	m.UpdateIntervalsTable(nil)
+	m.UpdateMatchesTable(nil)
+	m.UpdateGraphs(nil)
+	m.UpdateRawView(nil)
+}
+
+func (m *Model) CancelQuery() tea.Cmd {
+	if m.cancelQuery != nil {
+		m.cancelQuery()
+		m.cancelQuery = nil
+	}
+
+	m.CancelLoadMore()
+
+	// bump the generation so the late ResultMsg is dropped
+	m.queryGen += 1
+	m.queryInFlight = false
+
+	m.setMsg("Query canceled")
+	m.setState(TYPING)
+
+	m.textarea.Focus()
+
+	return textarea.Blink
 }
 
 func (m *Model) HighlightRow(row table.Row) tea.Cmd {
@@ -183,16 +716,7 @@ func (m *Model) HighlightRow(row table.Row) tea.Cmd {
 			update: func(m *Model) {
 				// m.otherMsg = fmt.Sprintf("row: %v", row[0])
 
-				// have to reconstruct the group key omg
-				group := map[string]any{}
-
-				for i, key := range m.queryMeta.orderedGroupKeys {
-					group[key] = row[i]
-				}
-
-				groupKey := getGroupKey(m.queryMeta.orderedGroupKeys, group)
-
-				m.highlightedGroup = groupKey
+				m.highlightedGroup = m.rowGroupKey(row)
 
 				m.UpdateGraphs(m.query.result)
 				// m.UpdateTotals(m.query.result)
@@ -201,6 +725,64 @@ func (m *Model) HighlightRow(row table.Row) tea.Cmd {
 	}
 }
 
+// have to reconstruct the group key omg
+func (m Model) rowGroupKey(row table.Row) string {
+	group := map[string]any{}
+
+	for i, key := range m.queryMeta.orderedGroupKeys {
+		group[key] = row[i]
+	}
+
+	return getGroupKey(m.queryMeta.orderedGroupKeys, group)
+}
+
+// selected groups stay in color next to the highlighted one, and stay
+// selected across refreshes
+func (m *Model) ToggleSelectedGroup() {
+	if m.totalsTable == nil || m.queryMeta == nil || len(m.totalsTable.SelectedRow()) == 0 {
+		return
+	}
+
+	group := m.rowGroupKey(m.totalsTable.SelectedRow())
+
+	if m.selectedGroups[group] {
+		delete(m.selectedGroups, group)
+	} else {
+		m.selectedGroups[group] = true
+	}
+
+	m.UpdateGraphs(m.query.result)
+}
+
+// with a group highlighted or selected, every other one is dimmed
+func (m Model) groupDimmed(group string) bool {
+	if m.highlightedGroup == "" && len(m.selectedGroups) == 0 {
+		return false
+	}
+
+	return group != m.highlightedGroup && !m.selectedGroups[group]
+}
+
+// all series back in color, the totals table is blurred so the next move
+// in it highlights again
+func (m *Model) ClearHighlight() {
+	if m.highlightedGroup == "" && len(m.selectedGroups) == 0 {
+		return
+	}
+
+	m.highlightedGroup = ""
+	m.selectedGroups = map[string]bool{}
+
+	if m.totalsTable != nil {
+		s := table.DefaultStyles()
+		s.Selected = lipgloss.NewStyle()
+		m.totalsTable.SetStyles(s)
+		m.totalsTable.Blur()
+	}
+
+	m.UpdateGraphs(m.query.result)
+}
+
 func (m *Model) UpdateQuery(msg ResultMsg) {
 	// set the query data
 	m.query = &Query{
@@ -222,20 +804,32 @@ func (m *Model) UpdateQueryMeta(result *axiomQuery.Result) {
 	var intervals = len(result.Buckets.Series)
 	var groups = []string{}
 
-	// get the length of the series
-	// iterate over result.Buckets.Series
+	// groups don't all have to share the same keys, so take the union
+	// across every interval and the totals
+	addGroupKeys := func(group map[string]any) {
+		for key := range group {
+			if !stringInSlice(key, orderedGroupKeys) {
+				orderedGroupKeys = append(orderedGroupKeys, key)
+			}
+		}
+	}
+
 	for _, interval := range result.Buckets.Series {
 		for _, group := range interval.Groups {
-			if len(orderedGroupKeys) == 0 && len(group.Group) > 0 {
-				// iterate over group.Group keys
-				for key := range group.Group {
-					orderedGroupKeys = append(orderedGroupKeys, key)
-				}
+			addGroupKeys(group.Group)
+		}
+	}
 
-				// sort orderedGroupKeys
-				sort.Strings(orderedGroupKeys)
-			}
+	for _, total := range result.Buckets.Totals {
+		addGroupKeys(total.Group)
+	}
 
+	sort.Strings(orderedGroupKeys)
+
+	// get the length of the series
+	// iterate over result.Buckets.Series
+	for _, interval := range result.Buckets.Series {
+		for _, group := range interval.Groups {
 			if (len(group.Aggregations)) > opsCount {
 				opsCount = len(group.Aggregations)
 			}
@@ -251,8 +845,8 @@ func (m *Model) UpdateQueryMeta(result *axiomQuery.Result) {
 
 	var ops = []Op{}
 
-	for _, total := range result.Buckets.Totals {
-		for _, aggregation := range total.Aggregations {
+	addOps := func(aggregations []axiomQuery.EntryGroupAgg) {
+		for _, aggregation := range aggregations {
 			idx := slices.IndexFunc(ops, func(op Op) bool {
 				return op.name == aggregation.Alias
 			})
@@ -265,16 +859,24 @@ func (m *Model) UpdateQueryMeta(result *axiomQuery.Result) {
 		}
 	}
 
-	sort.Strings(groups)
+	for _, total := range result.Buckets.Totals {
+		addOps(total.Aggregations)
+	}
 
-	groupColors := map[string]asciigraph.AnsiColor{}
+	// without totals the series still name the ops, in the same order
+	if len(result.Buckets.Totals) == 0 {
+		for _, interval := range result.Buckets.Series {
+			for _, group := range interval.Groups {
+				addOps(group.Aggregations)
+			}
+		}
+	}
 
-	for _, group := range groups {
-		hashed := hash(group)
-		colorIdx := hashed % len(COLORS)
+	sort.Strings(groups)
 
-		groupColors[group] = COLORS[colorIdx]
-	}
+	groupColors := assignGroupColors(groups, m.theme.colors)
+
+	rankedGroups := rankGroups(result, orderedGroupKeys, groups, ops)
 
 	m.queryMeta = &QueryMeta{
 		orderedGroupKeys: orderedGroupKeys,
@@ -283,6 +885,7 @@ func (m *Model) UpdateQueryMeta(result *axiomQuery.Result) {
 		groups:           groups,
 		ops:              ops,
 		groupColors:      groupColors,
+		rankedGroups:     rankedGroups,
 	}
 }
 
@@ -291,6 +894,9 @@ func (m *Model) UpdateMatchesTable(result *axiomQuery.Result) {
 
 	if result == nil || len(result.Matches) == 0 {
 		m.matchesTable = nil
+		m.matchesColumns = nil
+		m.matches = nil
+		m.detailsFocused = false
 	} else {
 
 		columns := []table.Column{
@@ -300,49 +906,77 @@ func (m *Model) UpdateMatchesTable(result *axiomQuery.Result) {
 			},
 		}
 
-		var data = result.Matches[0].Data
+		datasetField := getDatasetField(result.Matches)
+
+		// columns come from the unfiltered matches so they don't jump around
+		for _, field := range matchColumnFields(result.Matches, m.matchColumns, datasetField) {
+			width := 10
+
+			if field == datasetField {
+				width = 20
+			}
 
-		// iterate over all the keys in data
-		for k := range data {
 			columns = append(columns, table.Column{
-				Title: k,
-				Width: 10,
+				Title: field,
+				Width: width,
 			})
 		}
 
+		m.matches = filterMatches(result.Matches, m.matchesFilter, m.formatTime)
+
 		rows := []table.Row{}
 
-		// iterate over all of result.Matches
-		for _, match := range result.Matches {
+		// iterate over all of the visible matches
+		for _, match := range m.matches {
 			row := table.Row{}
 
-			row = append(row, match.Time.String())
+			row = append(row, m.formatTime(match.Time))
 
 			// iterate over all the columns
 			for _, column := range columns[1:] {
-				var value = match.Data[column.Title]
+				var value, ok = match.Data[column.Title]
+
+				// not every match has every field
+				if !ok || value == nil {
+					row = append(row, "")
+					continue
+				}
 
 				switch value.(type) {
 				case string:
 					row = append(row, value.(string))
 				case int:
-					row = append(row, fmt.Sprintf("%v", value.(int)))
+					row = append(row, m.formatNumber(float64(value.(int))))
 				case float64:
-					row = append(row, fmt.Sprintf("%v", value.(float64)))
+					row = append(row, m.formatNumber(value.(float64)))
 				default:
 					row = append(row, fmt.Sprintf("%v", value))
 				}
 			}
 
+			row = append(row, m.matchAges.marker(matchKey(match)))
+
 			// append a table.Row to rows
 			rows = append(rows, row)
 		}
 
+		columns = append(columns, NEW_ROW_COLUMN)
+
+		// size the _time column to the chosen format
+		timeWidth := len(columns[0].Title)
+
+		for _, row := range rows {
+			timeWidth = maxInt(timeWidth, len(row[0]))
+		}
+
+		columns[0].Width = timeWidth
+
 		t := table.New(
 			table.WithColumns(columns),
 			table.WithRows(rows),
 			table.WithFocused(true),
-			table.WithHeight(20),
+			table.WithHeight(DEFAULT_MATCHES_HEIGHT),
+			table.WithKeyMap(initTableKeyMap()),
 		)
 
 		s := table.DefaultStyles()
@@ -350,19 +984,116 @@ func (m *Model) UpdateMatchesTable(result *axiomQuery.Result) {
 		t.SetStyles(s)
 
 		m.matchesTable = &t
+		m.matchesColumns = columns
+		m.UpdateMatchDetails()
+
+		// with no totals to navigate the matches get the keys straight away
+		if m.totalsTable == nil {
+			m.UpdateMatchesHighlight()
+		}
+	}
+}
+
+func (m *Model) StartFilter() tea.Cmd {
+	m.filtering = true
+	m.filterInput.SetValue(m.matchesFilter)
+	m.filterInput.CursorEnd()
+
+	return m.filterInput.Focus()
+}
+
+// filters as you type, enter keeps the filter and esc clears it
+func (m *Model) UpdateFilter(msg tea.KeyMsg) tea.Cmd {
+	var cmd tea.Cmd
+
+	switch msg.String() {
+	case "enter":
+		m.filtering = false
+		m.filterInput.Blur()
+
+		return nil
+	case "esc":
+		m.filtering = false
+		m.filterInput.Blur()
+		m.filterInput.SetValue("")
+	default:
+		m.filterInput, cmd = m.filterInput.Update(msg)
+	}
+
+	m.matchesFilter = m.filterInput.Value()
+	m.UpdateMatchesTable(m.query.result)
+
+	return cmd
+}
+
+// keep the highlighted match in sync with the table cursor
+func (m *Model) UpdateMatchesHighlight() {
+	if len(m.matchesTable.Rows()) == 0 {
+		m.matchesTableHighlightedIdx = -1
+	} else {
+		m.matchesTableHighlightedIdx = m.matchesTable.Cursor()
+	}
+
+	m.UpdateMatchDetails()
+}
+
+// the matches get whatever height the rest of the view leaves, measured by
+// rendering it with the table at its smallest
+func (m *Model) LayoutMatchesTable() {
+	if m.matchesTable == nil || m.height == 0 || !m.ready || m.showHelp {
+		return
+	}
+
+	if m.viewMode != "all" && m.viewMode != "tables" {
+		return
+	}
+
+	m.matchesTable.SetHeight(MIN_MATCHES_HEIGHT)
+
+	spare := m.height - lipgloss.Height(m.View())
+
+	m.matchesTable.SetHeight(MIN_MATCHES_HEIGHT + maxInt(0, spare))
+}
+
+// load the highlighted match into the details viewport
+func (m *Model) UpdateMatchDetails() {
+	if m.matchesTableHighlightedIdx == -1 || m.matchesTableHighlightedIdx >= len(m.matches) {
+		m.detailsFocused = false
+		m.detailsViewport.SetContent("")
+		return
 	}
+
+	match := m.matches[m.matchesTableHighlightedIdx]
+	match.Time = match.Time.In(m.location)
+	match.SysTime = match.SysTime.In(m.location)
+
+	str := marshalMatch(match, orderFields(match.Data, m.detailFields, m.detailFieldsOnly))
+
+	lines := strings.Count(str, "\n") + 1
+
+	// inside the padding and focus border of the pane
+	m.detailsViewport.Width = maxInt(20, m.width-6)
+	m.detailsViewport.Height = minInt(lines, maxInt(5, m.height/3))
+	m.detailsViewport.GotoTop()
+
+	m.detailsJson = str
+	m.detailSearchIdx = 0
+	m.RenderMatchDetails()
 }
 
-func (m *Model) UpdateMatchesHighlight(inc int) {
-	nextIdx := m.matchesTableHighlightedIdx + inc
+func (m *Model) ScrollMatchDetails(msg tea.KeyMsg) tea.Cmd {
+	var cmd tea.Cmd
 
-	if nextIdx < 0 {
-		nextIdx = 0
-	} else if nextIdx >= len(m.matchesTable.Rows()) {
-		nextIdx = len(m.matchesTable.Rows()) - 1
+	switch msg.String() {
+	case "g", "home":
+		m.detailsViewport.GotoTop()
+	case "G", "end":
+		m.detailsViewport.GotoBottom()
+	default:
+		m.detailsViewport, cmd = m.detailsViewport.Update(msg)
 	}
 
-	m.matchesTableHighlightedIdx = nextIdx
+	return cmd
 }
 
 func (m *Model) UpdateGraphs(result *axiomQuery.Result) {
@@ -371,20 +1102,19 @@ func (m *Model) UpdateGraphs(result *axiomQuery.Result) {
 		return
 	}
 
-	data := make([][]float64, 4)
+	graphs := m.makeGraphs(result) // One for each aggregation
+	graphGroups := m.graphGroups()
 
-	for i := 0; i < 4; i++ {
-		for x := -20; x <= 20; x++ {
-			v := math.NaN()
-			if r := 20 - i; x >= -r && x <= r {
-				v = math.Sqrt(math.Pow(float64(r), 2)-math.Pow(float64(x), 2)) / 2
-			}
+	// the other series comes after the graphed groups
+	otherIdx := len(graphGroups)
+	hasOther := otherIdx < len(m.queryMeta.groups)
 
-			data[i] = append(data[i], v)
-		}
-	}
+	// which series got at least one number, per graph
+	hasValue := make([][]bool, len(graphs))
 
-	graphs := m.makeGraphs() // One for each aggregation
+	for i, graph := range graphs {
+		hasValue[i] = make([]bool, len(graph.data))
+	}
 
 	// for each Interval in result.Buckets.Series
 	for intervalIdx, interval := range result.Buckets.Series {
@@ -395,27 +1125,85 @@ func (m *Model) UpdateGraphs(result *axiomQuery.Result) {
 			// m.otherMsg = fmt.Sprintf("groupKey: %v groups: %v", groupKey, m.queryMeta.groups)
 
 			// get the index of groupKey in m.queryMeta.groupKeys
-			graphsDataIdx := sort.SearchStrings(m.queryMeta.groups, groupKey)
+			// skip groups we don't know about at all
+			if idx := sort.SearchStrings(m.queryMeta.groups, groupKey); idx >= len(m.queryMeta.groups) || m.queryMeta.groups[idx] != groupKey {
+				continue
+			}
+
+			graphsDataIdx := sort.SearchStrings(graphGroups, groupKey)
+			isOther := graphsDataIdx >= len(graphGroups) || graphGroups[graphsDataIdx] != groupKey
+
+			if isOther && !hasOther {
+				continue
+			}
 
 			// for each Aggregation in EntryGroup.Aggregations
 			for graphIdx, aggregation := range group.Aggregations {
-				intervalValue := math.NaN()
-				// check if aggregation.Value is a float64
-				// if not set it to NaN
-				if _, ok := aggregation.Value.(float64); ok {
-					intervalValue = aggregation.Value.(float64)
+				if graphIdx >= len(graphs) {
+					break
 				}
 
+				// non-numeric values are plotted as NaN
+				intervalValue := toFloat64(aggregation.Value)
+
 				graph := graphs[graphIdx]
+				seriesIdx := graphsDataIdx
+
+				if isOther {
+					seriesIdx = otherIdx
+				}
+
+				if !math.IsNaN(intervalValue) {
+					hasValue[graphIdx][seriesIdx] = true
+				}
+
+				if isOther {
+					// sum the excluded groups so the graph still adds up
+					if !math.IsNaN(intervalValue) {
+						graph.data[otherIdx][intervalIdx] += intervalValue
+					}
+
+					continue
+				}
+
 				data := graph.data[graphsDataIdx]
 				data[intervalIdx] = intervalValue
 			}
 		}
 	}
 
+	for i, graph := range graphs {
+		graphs[i] = markOverThreshold(dropEmptySeries(graph, hasValue[i]))
+	}
+
+	if m.compare {
+		m.addPreviousSeries(graphs)
+	}
+
 	m.graphs = &graphs
 }
 
+// a series that's NaN or absent in every interval would plot as a flat line
+// at 0, so it's listed as having no data instead
+func dropEmptySeries(graph GraphData, hasValue []bool) GraphData {
+	kept := graph
+	kept.groups, kept.labels, kept.data, kept.colors = nil, nil, nil, nil
+
+	for i, group := range graph.groups {
+		if !hasValue[i] {
+			kept.noData = append(kept.noData, group)
+			continue
+		}
+
+		kept.groups = append(kept.groups, group)
+		kept.labels = append(kept.labels, graph.labels[i])
+		kept.data = append(kept.data, graph.data[i])
+		kept.colors = append(kept.colors, graph.colors[i])
+	}
+
+	return kept
+}
+
 func (m *Model) UpdateTotals(result *axiomQuery.Result) {
 	if result == nil || len(result.Buckets.Totals) == 0 {
 		m.totalsTable = nil
@@ -438,24 +1226,75 @@ func (m *Model) UpdateTotals(result *axiomQuery.Result) {
 		})
 	}
 
-	rows := []table.Row{}
+	// trend of the first op, only the most recent intervals when there are many
+	trendWidth := minInt(m.queryMeta.intervals, MAX_SPARKLINE_WIDTH)
+
+	if len(m.queryMeta.ops) > 0 {
+		columns = append(columns, table.Column{
+			Title: "trend",
+			Width: maxInt(trendWidth, len("trend")),
+		})
+	}
+
+	rows := []table.Row{}
 
 	for _, total := range result.Buckets.Totals {
 		row := table.Row{}
 		for _, orderedKey := range m.queryMeta.orderedGroupKeys {
-			row = append(row, fmt.Sprintf("%v", total.Group[orderedKey]))
+			row = append(row, groupValue(total.Group, orderedKey))
 		}
 
 		for _, aggregation := range total.Aggregations {
 			row = append(row, fmt.Sprintf("%v", aggregation.Value))
 		}
 
+		// keep the trend in its column when a total is missing aggregations
+		for i := len(total.Aggregations); i < len(m.queryMeta.ops); i++ {
+			row = append(row, "")
+		}
+
+		if len(m.queryMeta.ops) > 0 {
+			groupKey := getGroupKey(m.queryMeta.orderedGroupKeys, total.Group)
+			values := groupSeries(result, m.queryMeta.orderedGroupKeys, groupKey, m.queryMeta.ops[0].name)
+
+			row = append(row, sparkline(values[len(values)-trendWidth:]))
+		}
+
+		row = append(row, m.totalAges.marker(totalKey(total)))
+
 		rows = append(rows, row)
 	}
 
+	columns = append(columns, NEW_ROW_COLUMN)
+
+	if m.sortCol >= len(columns) {
+		m.sortCol = -1
+	}
+
+	if m.sortCol != -1 {
+		sortRows(rows, m.sortCol, m.sortAsc)
+
+		arrow := " ▼"
+		if m.sortAsc {
+			arrow = " ▲"
+		}
+
+		columns[m.sortCol].Title += arrow
+	}
+
+	// format after sorting, which needs the raw numbers
+	keysCount := len(m.queryMeta.orderedGroupKeys)
+
+	for _, row := range rows {
+		for opIdx, op := range m.queryMeta.ops {
+			row[keysCount+opIdx] = m.formatOpValue(op.name, row[keysCount+opIdx])
+		}
+	}
+
 	t := table.New(
 		table.WithColumns(columns),
 		table.WithRows(rows),
+		table.WithKeyMap(initTableKeyMap()),
 	)
 
 	s := table.DefaultStyles()
@@ -467,38 +1306,183 @@ func (m *Model) UpdateTotals(result *axiomQuery.Result) {
 	m.totalsTable = &t
 }
 
+var TOP_N_STEPS = []int{0, 3, 5, 10}
+
+// all groups, then the top 3, 5 and 10 (from --top, the next step up)
+func (m *Model) CycleTopN() tea.Cmd {
+	next := 0
+
+	for _, step := range TOP_N_STEPS {
+		if step > m.topN {
+			next = step
+			break
+		}
+	}
+
+	m.topN = next
+
+	if m.topN == 0 {
+		m.setMsg("Graphing all groups")
+	} else {
+		m.setMsg(fmt.Sprintf("Graphing the top %v groups", m.topN))
+	}
+
+	cmd := m.SavePrefs(func(prefs *Prefs) {
+		value := m.topN
+		prefs.Top = &value
+	})
+
+	if m.query.result != nil {
+		m.UpdateGraphs(m.query.result)
+	}
+
+	return cmd
+}
+
+// the sorted groups to plot, limited to the top N when set
+func (m *Model) graphGroups() []string {
+	if m.topN <= 0 || m.topN >= len(m.queryMeta.groups) {
+		return m.queryMeta.groups
+	}
+
+	top := append([]string{}, m.queryMeta.rankedGroups[:m.topN]...)
+
+	sort.Strings(top)
+
+	return top
+}
+
+// cycle through the totals columns, ending back on Axiom's order
+func (m *Model) CycleTotalsSort() tea.Cmd {
+	columnsCount := len(m.queryMeta.orderedGroupKeys) + len(m.queryMeta.ops)
+
+	m.sortCol += 1
+
+	if m.sortCol >= columnsCount {
+		m.sortCol = -1
+	}
+
+	return m.SortTotals()
+}
+
+func (m *Model) ToggleTotalsSortOrder() tea.Cmd {
+	m.sortAsc = !m.sortAsc
+
+	return m.SortTotals()
+}
+
+func (m *Model) SortTotals() tea.Cmd {
+	focused := m.totalsTable.Focused()
+
+	m.UpdateTotals(m.query.result)
+
+	if !focused {
+		return nil
+	}
+
+	// keep the highlight on whatever row is selected after sorting
+	m.totalsTable.Focus()
+
+	return m.HighlightRow(m.totalsTable.SelectedRow())
+}
+
+// a new countdown only starts once a result is in, and supersedes any
+// countdown still ticking from before
 func (m *Model) SetRefreshing() tea.Cmd {
-	m.refreshTimeout = 5 // seconds
+	m.refreshTimeout = m.refreshInterval
 	m.setState(REFRESHING)
 
+	m.refreshGen += 1
+	gen := m.refreshGen
+
 	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
-		return RefreshMsg{}
+		return RefreshMsg{gen: gen}
+	})
+}
+
+// the next preset up from the interval there is now, which can be one
+// given with --refresh
+func (m *Model) CycleRefreshInterval() tea.Cmd {
+	next := REFRESH_INTERVALS[0]
+
+	for _, interval := range REFRESH_INTERVALS {
+		if interval > m.refreshInterval {
+			next = interval
+			break
+		}
+	}
+
+	m.refreshInterval = next
+	m.setMsg(fmt.Sprintf("Refreshing %v seconds after each result", next))
+
+	cmd := m.SavePrefs(func(prefs *Prefs) {
+		value := m.refreshInterval
+		prefs.RefreshInterval = &value
 	})
+
+	// the countdown running now is cut short rather than run over
+	m.refreshTimeout = minInt(m.refreshTimeout, m.refreshInterval)
+
+	return cmd
 }
 
 func (m *Model) UpdateRefreshing() tea.Cmd {
 	m.refreshTimeout -= 1
 
+	gen := m.refreshGen
+	rerun := m.refreshTimeout <= 1
+
 	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
 
-		if (m.refreshTimeout) <= 1 {
-			return ReRunMsg{}
+		if rerun {
+			return ReRunMsg{gen: gen}
 		} else {
-			return RefreshMsg{}
+			return RefreshMsg{gen: gen}
 		}
 	})
 }
 
+// the text of the line the cursor is on, split at the cursor
+func (m Model) cursorLine() (string, string) {
+	lines := strings.Split(m.textarea.Value(), "\n")
+	line := []rune(lines[minInt(m.textarea.Line(), len(lines)-1)])
+
+	info := m.textarea.LineInfo()
+	col := minInt(info.StartColumn+info.ColumnOffset, len(line))
+
+	return string(line[:col]), string(line[col:])
+}
+
+// the new line keeps the indentation of the one it was split from
+func (m *Model) InsertIndentedNewline() {
+	before, after := m.cursorLine()
+
+	m.textarea.InsertString("\n" + aplIndent(before+after, after))
+}
+
 func (m *Model) UpdatePulse() tea.Cmd {
+	// a key press lets the current cycle run out before showing the query
+	if m.splashClosing && m.pulseStep <= 0 {
+		m.ready = true
+
+		return nil
+	}
+
 	if m.pulseStep <= 0 {
-		m.pulseStep = 9
+		m.pulseStep = len(m.theme.pulseColors) - 1
 	} else {
 		m.pulseStep -= 1
 	}
 
+	interval := time.Millisecond * 150
+
+	if m.splashClosing {
+		interval = time.Millisecond * 30
+	}
+
 	return tea.Tick(
-		// Send a pulse update every 150ms
-		time.Millisecond*150, func(t time.Time) tea.Msg {
+		// Send a pulse update every 150ms, faster while closing
+		interval, func(t time.Time) tea.Msg {
 
 			return PulseMsg{}
 		})
@@ -511,22 +1495,71 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	)
 
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+		// padding and focus border plus the textarea's own prompt and border
+		m.textarea.SetWidth(maxInt(20, m.width-6))
+
+		// leave room for the header above the raw result
+		m.rawViewport.Width = maxInt(20, m.width-2)
+		m.rawViewport.Height = maxInt(5, m.height-15)
+
 	case tea.KeyMsg:
 
 		if !m.ready {
-			m.ready = true
+			if msg.String() == "ctrl+c" {
+				return m, tea.Quit
+			}
+
+			// the pulse already running finishes the cycle and flips ready
+			m.splashClosing = true
 
 			return m, tea.Batch(cmds...)
 		}
 
 		switch msg.String() {
 		case "ctrl+c":
+			m.FlushPrefs()
+
 			return m, tea.Quit
+		case "f1":
+			m.showHelp = !m.showHelp
 		default:
+			if m.showHelp {
+				switch msg.String() {
+				case "?", "esc":
+					m.showHelp = false
+				}
+
+				break
+			}
+
 			switch m.state {
 			case TYPING:
+				if m.bookmarkMode != BOOKMARKS_CLOSED {
+					cmds = append(cmds, m.UpdateBookmarks(msg))
+					break
+				}
+
+				// any other key accepts the completion that's showing
+				if msg.String() != "tab" {
+					m.CloseCompletions()
+				}
+
 				switch msg.String() {
-				case "enter":
+				case "tab":
+					m.Complete()
+				case "ctrl+l":
+					m.ClearQuery()
+				case "ctrl+y":
+					cmds = append(cmds, m.CopyQuery())
+				case "ctrl+b":
+					cmds = append(cmds, m.StartBookmarkSave())
+				case "ctrl+o":
+					m.StartBookmarkList()
+				case "ctrl+s", "alt+enter":
 					query := strings.TrimSpace(m.textarea.Value())
 
 					// debug
@@ -535,13 +1568,39 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					// }
 
 					if query != "" {
+						// only a hint, the query is still sent
+						m.queryWarning = validateApl(query)
+
 						cmds = append(cmds, m.RunQuery(query))
 					}
+				case "enter":
+					m.InsertIndentedNewline()
+				case "alt+1", "alt+2", "alt+3", "alt+4", "alt+5":
+					presetIdx, _ := strconv.Atoi(strings.TrimPrefix(msg.String(), "alt+"))
+
+					m.textarea.SetValue(setTimeRange(m.textarea.Value(), TIME_RANGE_PRESETS[presetIdx-1]))
 				default:
 					m.textarea, cmd = m.textarea.Update(msg)
-					cmds = append(cmds, cmd)
+					cmds = append(cmds, cmd, m.LoadDatasets())
+				}
+			case QUERYING:
+				switch msg.String() {
+				case "esc":
+					cmds = append(cmds, m.CancelQuery())
+				case "?":
+					m.showHelp = true
 				}
 			case REFRESHING:
+				if m.filtering {
+					cmds = append(cmds, m.UpdateFilter(msg))
+					break
+				}
+
+				if m.detailSearching {
+					cmds = append(cmds, m.UpdateDetailSearch(msg))
+					break
+				}
+
 				switch msg.String() {
 				case "esc":
 					if !m.textarea.Focused() {
@@ -551,8 +1610,139 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 					m.setState(TYPING)
 
-				default:
+				case "?":
+					m.showHelp = true
+
+				case "r":
+					// the countdown restarts once the result is in
+					if m.query.apl != "" {
+						cmds = append(cmds, m.RefreshQuery())
+					}
+
+				case "t":
+					if m.queryMeta != nil {
+						cmds = append(cmds, m.CycleTopN())
+					}
+
+				case "v":
+					cmds = append(cmds, m.CycleViewMode())
+
+				case "V":
+					m.ToggleRawView()
+
+				case "p":
+					cmds = append(cmds, m.CyclePrecision())
+
+				case "f":
+					cmds = append(cmds, m.CycleRefreshInterval())
+
+				case "T":
+					cmds = append(cmds, m.CycleTheme())
+
+				case "z":
+					cmds = append(cmds, m.ToggleTimezone())
+
+				case "+":
+					cmds = append(cmds, m.ResizeGraphs(1))
+
+				case "-":
+					cmds = append(cmds, m.ResizeGraphs(-1))
+
+				case "w":
+					cmds = append(cmds, m.OpenInWeb())
+
+				case "i":
+					m.ToggleIntervals()
+
+				case "b":
+					cmds = append(cmds, m.ToggleCompare())
+
+				case "m":
+					cmds = append(cmds, m.LoadMoreMatches())
+
+				case "a":
+					cmds = append(cmds, m.EditLastQuery())
+
+				case "o":
+					m.ToggleOverlay()
+
+				case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+					m.ToggleOp(int(msg.Runes[0] - '1'))
+
+				case "e":
+					cmds = append(cmds, m.ExportGraphText())
+
+				case "E":
+					cmds = append(cmds, m.ExportGraphPNG())
+
+				case "c":
+					m.ClearHighlight()
+
+				case " ":
+					if !m.matchesFocused {
+						m.ToggleSelectedGroup()
+					}
+
+				case "x":
+					m.matchDetailsExpanded = !m.matchDetailsExpanded
+					m.detailsFocused = m.detailsFocused && m.matchDetailsExpanded
+
+				case "left", "h", "right", "l":
+					if m.focusedPane == PANE_MATCHES {
+						step := 1
+
+						if msg.String() == "left" || msg.String() == "h" {
+							step = -1
+						}
+
+						m.ScrollMatchColumns(step)
+					}
+
+				case "tab":
+					cmds = append(cmds, m.CycleFocus(1))
+
+				case "shift+tab":
+					cmds = append(cmds, m.CycleFocus(-1))
+
+				case "/":
+					// search the details while they have the keys
+					if m.detailsFocused {
+						cmds = append(cmds, m.StartDetailSearch())
+					} else if m.matchesTable != nil {
+						cmds = append(cmds, m.StartFilter())
+					}
+
+				case "n", "N":
+					if m.detailsFocused {
+						step := 1
+
+						if msg.String() == "N" {
+							step = -1
+						}
+
+						m.JumpDetailSearch(step)
+					}
+
+				case "s":
+					if m.totalsTable != nil {
+						cmds = append(cmds, m.CycleTotalsSort())
+					}
+
+				case "S":
 					if m.totalsTable != nil {
+						cmds = append(cmds, m.ToggleTotalsSortOrder())
+					}
+
+				default:
+					if m.viewMode == "raw" {
+						cmds = append(cmds, m.ScrollRawView(msg))
+					} else if m.detailsFocused {
+						cmds = append(cmds, m.ScrollMatchDetails(msg))
+					} else if m.showIntervals && m.intervalsTable != nil && !m.matchesFocused {
+						intervalsTable, cmd := m.intervalsTable.Update(msg)
+						m.intervalsTable = &intervalsTable
+						cmds = append(cmds, cmd)
+					} else if m.totalsTable != nil && !m.matchesFocused {
 						if !m.totalsTable.Focused() {
 							m.totalsTable.Focus()
 							cmds = append(cmds, m.HighlightRow(m.totalsTable.SelectedRow()))
@@ -567,12 +1757,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						m.matchesTable = &matchesTable
 						cmds = append(cmds, cmd)
 
-						switch msg.String() {
-						case "down":
-							m.UpdateMatchesHighlight(1)
-						case "up":
-							m.UpdateMatchesHighlight(-1)
-						}
+						m.UpdateMatchesHighlight()
 					}
 
 				}
@@ -580,18 +1765,110 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		}
 	case ResultMsg:
+		if msg.gen < m.queryGen {
+			// stale result of a canceled or superseded query
+			break
+		}
+
+		if msg.err != nil {
+			if cmd, retrying := m.RetryQuery(msg); retrying {
+				cmds = append(cmds, cmd)
+				break
+			}
+		}
+
+		// a manually run query starts unfiltered
+		if !m.autoRefresh {
+			m.matchesFilter = ""
+		}
+
+		m.setMsg("")
+
+		if msg.recordErr != nil {
+			m.setMsg(fmt.Sprintf("Could not record the result: %v", msg.recordErr))
+		}
+
+		m.cancelQuery = nil
+		m.queryInFlight = false
 		m.textarea.Blur()
-		m.highlightedGroup = ""
+
+		if msg.err == nil {
+			m.lastResultAt = time.Now()
+		}
+
+		// the same query run again keeps the cursors and highlight where they were
+		sameQuery := msg.apl == m.query.apl
+
+		var selection *tableSelection
+
+		if sameQuery {
+			selection = m.saveSelection()
+		}
+
+		var fresh int
+		msg, fresh = m.MergeTail(msg)
+
 		m.UpdateQuery(msg)
+
+		// a tail refresh doesn't move where the older matches start
+		if msg.since.IsZero() {
+			m.moreCursor = m.pageCursor(msg.result)
+		}
+
+		m.UpdateRowAges(msg.result)
 		m.UpdateQueryMeta(msg.result)
+
+		// a highlighted group that's gone from the result has nothing to show
+		if !sameQuery || m.queryMeta == nil || !stringInSlice(m.highlightedGroup, m.queryMeta.groups) {
+			m.highlightedGroup = ""
+		}
+
+		if !sameQuery {
+			m.selectedGroups = map[string]bool{}
+			m.matchColumnOffset = 0
+		}
+
+		m.UpdatePrevious(msg)
+		m.UpdateTotals(msg.result) // before the matches, which check for totals
+		m.UpdateIntervalsTable(msg.result)
 		m.UpdateMatchesTable(msg.result)
-		m.UpdateTotals(msg.result)
 		m.UpdateGraphs(msg.result)
+		m.UpdateRawView(msg.result)
+		m.UpdateLimitWarning(msg.result)
+
+		if msg.err == nil {
+			cmds = append(cmds, m.CheckThresholds())
+		}
+
+		if selection != nil {
+			m.restoreSelection(selection)
+		}
+
+		// the newest matches are on top, followed when the cursor was there
+		if fresh > 0 && m.matchesTable != nil && (selection == nil || selection.matchCursor <= 0) {
+			m.matchesTable.GotoTop()
+		}
+
+		// tell an empty result apart from one that's still loading
+		if msg.err == nil && m.queryMeta == nil && m.totalsTable == nil && m.matchesTable == nil && m.graphs == nil {
+			m.setMsg(NO_RESULTS_MSG)
+		}
+
 		cmd = m.SetRefreshing()
 		cmds = append(cmds, cmd)
 
 	case Msg:
 		msg.update(&m)
+	case MoreMatchesMsg:
+		m.AppendMatches(msg)
+	case FlashMsg:
+		if msg.gen == m.flashGen {
+			m.flashing = nil
+		}
+	case BellMsg:
+		m.bellRinging = false
+	case SavePrefsMsg:
+		cmds = append(cmds, m.WritePrefs(msg))
 	case spinner.TickMsg:
 		switch m.state {
 		case QUERYING:
@@ -599,16 +1876,51 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			cmds = append(cmds, cmd)
 		}
 	case RefreshMsg:
+		if msg.gen != m.refreshGen {
+			break
+		}
+
 		switch m.state {
 		case REFRESHING:
 			cmds = append(cmds, m.UpdateRefreshing())
 		}
 	case ReRunMsg:
+		// the result of a query still running restarts the countdown, a watched
+		// file coming back does too
+		if msg.gen != m.refreshGen || m.queryInFlight || m.watchMissing {
+			break
+		}
+
+		// picked up again once the terminal has focus
+		if !m.focused {
+			m.refreshPaused = true
+			break
+		}
+
 		switch m.state {
 		case REFRESHING:
-			cmds = append(cmds, m.RunQuery(m.query.apl))
+			cmds = append(cmds, m.RefreshQuery())
 		}
+	case tea.BlurMsg:
+		m.focused = false
+	case tea.FocusMsg:
+		m.focused = true
+
+		// only a refresh that was skipped while away is run right away
+		if m.refreshPaused && m.state == REFRESHING && !m.queryInFlight {
+			cmds = append(cmds, m.RefreshQuery())
+		}
+
+		m.refreshPaused = false
+	case DatasetsMsg:
+		m.SetDatasets(msg)
+	case WatchMsg:
+		cmd = m.UpdateWatchFile(msg)
+
+		// polled again with the mtime just seen
+		cmds = append(cmds, cmd, m.PollWatchFile(WATCH_INTERVAL))
 	case PulseMsg:
+		// the pulse stops rescheduling itself once the splash is gone
 		if !m.ready {
 			cmds = append(cmds, m.UpdatePulse())
 		}
@@ -617,6 +1929,24 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.textarea, cmd = m.textarea.Update(msg)
 			cmds = append(cmds, cmd)
 		}
+
+		if m.filtering {
+			m.filterInput, cmd = m.filterInput.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+
+		if m.bookmarkMode == BOOKMARKS_NAMING {
+			m.bookmarkInput, cmd = m.bookmarkInput.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+	}
+
+	m.UpdateFocusedPane()
+
+	// anything that can change what's above or below the matches
+	switch msg.(type) {
+	case tea.WindowSizeMsg, tea.KeyMsg, ResultMsg, Msg:
+		m.LayoutMatchesTable()
 	}
 
 	return m, tea.Batch(cmds...)
@@ -630,30 +1960,37 @@ func (m Model) ViewMatches() string {
 	if m.matchesTableHighlightedIdx != -1 {
 		s := table.DefaultStyles()
 		s.Selected = s.Selected.
-			Foreground(lipgloss.Color("229")).
-			Background(lipgloss.Color("57")).
+			Foreground(m.theme.highlightForeground).
+			Background(m.theme.highlightBackground).
 			Bold(false)
 		m.matchesTable.SetStyles(s)
 	}
 
-	return tableStyle.Render(m.matchesTable.View())
+	t, moreLeft, moreRight := m.scrolledMatchesTable()
+	parts := appendIfNotEmpty([]string{t.View()}, m.ViewMatchColumnsHint(moreLeft, moreRight))
+
+	return m.paneStyle(PANE_MATCHES).Render(lipgloss.JoinVertical(lipgloss.Left, parts...))
 }
 
 func (m Model) ViewTotals() string {
+	if m.showIntervals && m.intervalsTable != nil {
+		return m.ViewIntervals()
+	}
+
 	if m.totalsTable == nil {
 		return ""
 	}
 
 	if m.highlightedGroup != "" {
 		s := table.DefaultStyles()
-		s.Selected = s.Selected.Foreground(lipgloss.Color("229")).
-			Background(lipgloss.Color("57")).
+		s.Selected = s.Selected.Foreground(m.theme.highlightForeground).
+			Background(m.theme.highlightBackground).
 			Bold(false)
 
 		m.totalsTable.SetStyles(s)
 	}
 
-	return tableStyle.Render(m.totalsTable.View())
+	return m.paneStyle(PANE_TOTALS).Render(m.totalsTable.View())
 }
 
 func (m Model) ViewGraphs() string {
@@ -661,61 +1998,506 @@ func (m Model) ViewGraphs() string {
 		return ""
 	}
 
-	graphWidth := 50
-	graphHeight := 10
+	graphs := m.visibleGraphs()
+
+	if len(graphs) == 0 {
+		return ""
+	}
+
+	graphsPerRow, cellWidth := m.graphLayout(len(graphs))
+	graphHeight := m.graphHeight()
+
+	// the tallest stats set the height of every cell, so the grid lines up
+	statsHeight := 0
 
+	for _, graph := range graphs {
+		statsHeight = maxInt(statsHeight, lipgloss.Height(m.ViewSeriesStats(graph, cellWidth)))
+	}
+
+	// the plot is one row taller than its height plus the caption, so cells
+	// of flat series (which render shorter) still line up in the grid
 	focusedModelStyle := lipgloss.NewStyle().
-		Width(graphWidth+15).
-		Height(graphHeight).
+		Width(cellWidth).
+		Height(graphHeight+2+statsHeight).
 		Align(lipgloss.Left, lipgloss.Top).
 		BorderStyle(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color("69"))
+		BorderForeground(m.theme.accentColor)
 
 	var plots []string = []string{}
 
-	for _, graph := range *m.graphs {
-		styledGraph := focusedModelStyle.Render(asciigraph.PlotMany(graph.data, asciigraph.Precision(0), asciigraph.SeriesColors(
-			graph.colors...,
-		), asciigraph.Height(graphHeight), asciigraph.Width(graphWidth), asciigraph.Caption(graph.title)))
-
-		plots = append(plots, styledGraph)
-	}
+	for _, graph := range graphs {
+		precision := m.precision
 
-	return tableStyle.Render(lipgloss.JoinHorizontal(
-		lipgloss.Left,
-		plots...,
-	))
-}
+		if precision == AUTO_PRECISION {
+			precision = autoPrecision(graph.data)
+		}
 
-func (m Model) ViewSpinner() string {
-	if m.state != QUERYING {
-		return ""
-	}
+		options := []asciigraph.Option{
+			asciigraph.Precision(uint(precision)),
+			asciigraph.Height(graphHeight),
+			asciigraph.Caption(graph.title),
+		}
 
-	return lipgloss.JoinHorizontal(lipgloss.Left, m.spinner.View(), "Running query...")
-}
+		// asciigraph writes its own escape codes, lipgloss can't strip them
+		data, colors := withThresholdLine(graph)
 
-func (m Model) ViewRefreshTimeout() string {
-	if m.state != REFRESHING {
-		return ""
-	}
+		if !m.noColor {
+			options = append(options, asciigraph.SeriesColors(colors...))
+		}
 
-	return fmt.Sprintf("Refresh in %v", m.refreshTimeout)
-}
+		var plot string
 
-func (m Model) ViewMatchDetails() string {
-	if m.matchesTable == nil || m.matchesTableHighlightedIdx == -1 {
-		return ""
-	}
+		if len(graph.data) == 0 {
+			plot = lipgloss.Place(cellWidth, graphHeight+2, lipgloss.Center, lipgloss.Center, graph.title+"\n(no data)")
+		} else {
+			plot = m.numbers.LocalizeAxis(fitPlot(data, options, cellWidth))
+		}
 
-	str, _ := json.MarshalIndent(m.query.result.Matches[m.matchesTableHighlightedIdx], "", "  ")
+		// padded to the plot height first so the stats line up across cells
+		plot = lipgloss.JoinVertical(lipgloss.Left, lipgloss.PlaceVertical(graphHeight+2, lipgloss.Top, plot), m.ViewSeriesStats(graph, cellWidth))
 
-	return tableStyle.Render(string(str))
-}
+		style := focusedModelStyle
 
-func (m *Model) ViewSplashScreen() string {
+		if m.flashing[graph.op] {
+			style = style.BorderForeground(ansiColor(graph.thresholdColor))
+		}
 
-	splashStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(PULSE_STEP_COLORS[m.pulseStep]))
+		styledGraph := style.Render(plot)
+
+		plots = append(plots, styledGraph)
+	}
+
+	return tableStyle.Render(wrapGraphs(plots, graphsPerRow))
+}
+
+// from the height the graphs have now, so the first press starts from the
+// one picked for the window
+func (m *Model) ResizeGraphs(step int) tea.Cmd {
+	m.fixedGraphHeight = maxInt(MIN_GRAPH_HEIGHT, m.graphHeight()+step)
+	m.setMsg(fmt.Sprintf("Graph height: %v", m.fixedGraphHeight))
+
+	return m.SavePrefs(func(prefs *Prefs) {
+		value := m.fixedGraphHeight
+		prefs.GraphHeight = &value
+	})
+}
+
+// auto, then 0 through 4 decimals
+func (m *Model) CyclePrecision() tea.Cmd {
+	if m.precision >= 4 {
+		m.precision = AUTO_PRECISION
+	} else {
+		m.precision += 1
+	}
+
+	if m.precision == AUTO_PRECISION {
+		m.setMsg("Graph precision: auto")
+	} else {
+		m.setMsg(fmt.Sprintf("Graph precision: %v", m.precision))
+	}
+
+	return m.SavePrefs(func(prefs *Prefs) {
+		value := m.precision
+		prefs.Precision = &value
+	})
+}
+
+// more decimals the smaller the values get
+func autoPrecision(data [][]float64) int {
+	largest := 0.0
+
+	for _, series := range data {
+		for _, v := range series {
+			if !math.IsNaN(v) && math.Abs(v) > largest {
+				largest = math.Abs(v)
+			}
+		}
+	}
+
+	switch {
+	case largest < 1:
+		return 3
+	case largest < 10:
+		return 2
+	case largest < 100:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func graphRowsCount(count int, perRow int) int {
+	return (count + perRow - 1) / perRow
+}
+
+// lay the graph cells out in a grid of perRow columns
+func wrapGraphs(plots []string, perRow int) string {
+	var rows []string = []string{}
+
+	for row := 0; row < graphRowsCount(len(plots), perRow); row++ {
+		start := row * perRow
+		end := minInt(start+perRow, len(plots))
+
+		rows = append(rows, lipgloss.JoinHorizontal(
+			lipgloss.Top,
+			plots[start:end]...,
+		))
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		rows...,
+	)
+}
+
+// how many graphs fit side by side and how wide each cell is inside its border
+func (m Model) graphLayout(count int) (int, int) {
+	if m.width == 0 || count == 0 {
+		// no size reported yet
+		return maxInt(count, 1), DEFAULT_GRAPH_WIDTH + GRAPH_LABEL_WIDTH
+	}
+
+	available := m.width - 2 // tableStyle padding
+
+	perRow := available / (MIN_GRAPH_WIDTH + GRAPH_CELL_OVERHEAD)
+	perRow = maxInt(1, minInt(perRow, count))
+
+	cellWidth := available/perRow - 2 // border
+
+	// room for at least a column of plot past the labels, unless the
+	// terminal is narrower than that
+	cellWidth = maxInt(cellWidth, GRAPH_LABEL_WIDTH+1)
+
+	return perRow, maxInt(minInt(cellWidth, available-2), 1)
+}
+
+// the label width depends on the values and precision, so a plot wider than
+// its cell is drawn again narrower rather than left for lipgloss to wrap,
+// and a caption too long for the cell is cut
+func fitPlot(data [][]float64, options []asciigraph.Option, cellWidth int) string {
+	plotWidth := maxInt(cellWidth-GRAPH_LABEL_WIDTH, 1)
+	plot := asciigraph.PlotMany(copySeries(data), append(options, asciigraph.Width(plotWidth))...)
+
+	if over := lipgloss.Width(plot) - cellWidth; over > 0 && plotWidth > 1 {
+		plotWidth = maxInt(plotWidth-over, 1)
+		plot = asciigraph.PlotMany(copySeries(data), append(options, asciigraph.Width(plotWidth))...)
+	}
+
+	return lipgloss.NewStyle().MaxWidth(cellWidth).Render(plot)
+}
+
+// asciigraph stretches the series it's given to the plot width in place,
+// which would leave the graph with the stretched values for its stats
+func copySeries(data [][]float64) [][]float64 {
+	series := make([][]float64, len(data))
+
+	for i := range data {
+		series[i] = append([]float64{}, data[i]...)
+	}
+
+	return series
+}
+
+func (m Model) graphHeight() int {
+	if m.fixedGraphHeight != AUTO_GRAPH_HEIGHT {
+		return m.fixedGraphHeight
+	}
+
+	if m.height == 0 {
+		return DEFAULT_GRAPH_HEIGHT
+	}
+
+	return maxInt(5, minInt(20, m.height/4))
+}
+
+func (m Model) ViewTimeAxis() string {
+	if m.graphs == nil {
+		return ""
+	}
+
+	series := m.query.result.Buckets.Series
+	layout := "2006-01-02 15:04:05 MST"
+
+	start := series[0].StartTime.In(m.location)
+	end := series[len(series)-1].EndTime.In(m.location)
+	interval := series[0].EndTime.Sub(series[0].StartTime)
+
+	axisStyle := lipgloss.NewStyle().Foreground(m.theme.hintColor).PaddingLeft(1)
+
+	return axisStyle.Render(fmt.Sprintf("%v → %v · %v buckets of %v", start.Format(layout), end.Format(layout), len(series), interval))
+}
+
+// without a number for any of the ops the group isn't in any of the plots
+func (m Model) groupWithoutData(group string) bool {
+	for _, graph := range *m.graphs {
+		if !stringInSlice(group, graph.noData) {
+			return false
+		}
+	}
+
+	return len(*m.graphs) > 0
+}
+
+func (m Model) ViewLegend() string {
+	if m.graphs == nil || m.queryMeta == nil {
+		return ""
+	}
+
+	if overlay, _ := m.overlaid(m.shownOpGraphs()); overlay != nil {
+		return m.ViewOverlayLegend(*overlay)
+	}
+
+	var entries []string = []string{}
+
+	graphGroups := m.graphGroups()
+
+	for _, group := range graphGroups {
+		color := m.queryMeta.groupColors[group]
+
+		// dim the other groups the same way makeGraphs does
+		if m.groupDimmed(group) {
+			color = m.theme.dimColor
+		}
+
+		entryStyle := lipgloss.NewStyle().
+			Foreground(ansiColor(color)).
+			PaddingRight(2)
+
+		label := "■ " + group
+
+		if m.groupWithoutData(group) {
+			label += " (no data)"
+		}
+
+		entries = append(entries, entryStyle.Render(label))
+	}
+
+	if hidden := len(m.queryMeta.groups) - len(graphGroups); hidden > 0 {
+		color := m.theme.otherColor
+
+		if m.groupDimmed(OTHER_GROUP) {
+			color = m.theme.dimColor
+		}
+
+		entryStyle := lipgloss.NewStyle().
+			Foreground(ansiColor(color)).
+			PaddingRight(2)
+
+		entries = append(entries, entryStyle.Render(fmt.Sprintf("■ %v (%v groups outside the top %v)", OTHER_GROUP, hidden, m.topN)))
+	}
+
+	entries = appendIfNotEmpty(entries, m.ViewPreviousLegend())
+
+	return tableStyle.Render(lipgloss.JoinHorizontal(
+		lipgloss.Left,
+		entries...,
+	))
+}
+
+func (m Model) ViewSpinner() string {
+	if m.state != QUERYING {
+		return ""
+	}
+
+	label := "Running query..."
+
+	if m.retryAttempt > 0 {
+		label = m.msg
+	} else if m.autoRefresh {
+		label = "Refreshing..."
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Left, m.spinner.View(), label)
+}
+
+// the bar depletes with the countdown and is full again on each new cycle
+func (m Model) ViewRefreshTimeout() string {
+	if m.state != REFRESHING {
+		return ""
+	}
+
+	percent := float64(m.refreshTimeout) / float64(m.refreshInterval)
+
+	if m.refreshPaused {
+		return lipgloss.JoinHorizontal(lipgloss.Left, m.refreshProgress.ViewAs(0), " Paused while the terminal is in the background")
+	}
+
+	return lipgloss.JoinHorizontal(
+		lipgloss.Left,
+		m.refreshProgress.ViewAs(percent),
+		fmt.Sprintf(" Refresh in %v", m.refreshTimeout),
+	)
+}
+
+func (m Model) ViewHighlightedQuery() string {
+	apl := strings.TrimSpace(m.textarea.Value())
+
+	if apl == "" {
+		return ""
+	}
+
+	return lipgloss.NewStyle().PaddingLeft(1).Render(highlightApl(apl, m.theme.syntax))
+}
+
+// the spinner already shows the message while querying
+func (m Model) ViewMsg() string {
+	if m.msg == "" || m.state == QUERYING {
+		return ""
+	}
+
+	if m.msg == NO_RESULTS_MSG {
+		return tableStyle.Render(lipgloss.NewStyle().Bold(true).Foreground(m.theme.warningColor).Render(m.msg))
+	}
+
+	return tableStyle.Render(m.msg)
+}
+
+func (m Model) ViewTimeRangePresets() string {
+	if m.state != TYPING {
+		return ""
+	}
+
+	keyStyle := lipgloss.NewStyle().Foreground(m.theme.accentColor)
+
+	var presets []string = []string{}
+
+	for i, preset := range TIME_RANGE_PRESETS {
+		presets = append(presets, fmt.Sprintf("%v %v", keyStyle.Render(fmt.Sprintf("alt+%v", i+1)), preset))
+	}
+
+	return lipgloss.NewStyle().PaddingLeft(1).Render(strings.Join(presets, "  "))
+}
+
+func (m Model) ViewFilter() string {
+	if m.filtering {
+		return tableStyle.Render(m.filterInput.View())
+	}
+
+	if m.matchesFilter == "" || m.matchesTable == nil {
+		return ""
+	}
+
+	return tableStyle.Render(fmt.Sprintf("Filter: %v (%v of %v matches)", m.matchesFilter, len(m.matches), len(m.query.result.Matches)))
+}
+
+// the highlighted group as field=value pairs, taken from the totals entry
+// it was keyed from since its values could contain the ", " they're
+// joined with
+func highlightedGroupLabel(result *axiomQuery.Result, orderedGroupKeys []string, groupKey string) string {
+	for _, total := range result.Buckets.Totals {
+		if getGroupKey(orderedGroupKeys, total.Group) != groupKey {
+			continue
+		}
+
+		pairs := []string{}
+
+		for _, key := range orderedGroupKeys {
+			pairs = append(pairs, fmt.Sprintf("%v=%v", key, groupValue(total.Group, key)))
+		}
+
+		return strings.Join(pairs, ", ")
+	}
+
+	return groupKey
+}
+
+func (m Model) ViewHighlightedGroup() string {
+	if (m.highlightedGroup == "" && len(m.selectedGroups) == 0) || m.queryMeta == nil || m.query.result == nil {
+		return ""
+	}
+
+	hintStyle := lipgloss.NewStyle().Foreground(m.theme.hintColor)
+	lines := []string{}
+
+	if m.highlightedGroup != "" {
+		label := highlightedGroupLabel(m.query.result, m.queryMeta.orderedGroupKeys, m.highlightedGroup)
+		lines = append(lines, fmt.Sprintf("Filtered: %v  ", label)+hintStyle.Render("(c clears it, ↑/↓ in the totals picks another group)"))
+	}
+
+	if len(m.selectedGroups) > 0 {
+		labels := []string{}
+
+		for _, group := range m.queryMeta.groups {
+			if m.selectedGroups[group] {
+				labels = append(labels, highlightedGroupLabel(m.query.result, m.queryMeta.orderedGroupKeys, group))
+			}
+		}
+
+		// selected in an earlier result but gone from this one
+		if len(labels) < len(m.selectedGroups) {
+			labels = append(labels, fmt.Sprintf("(+%v not in this result)", len(m.selectedGroups)-len(labels)))
+		}
+
+		lines = append(lines, fmt.Sprintf("Selected: %v  ", strings.Join(labels, " | "))+hintStyle.Render("(space toggles the group in the totals, c clears)"))
+	}
+
+	return lipgloss.NewStyle().PaddingLeft(1).Render(strings.Join(lines, "\n"))
+}
+
+func (m Model) ViewMatchDetails() string {
+	if m.matchesTable == nil || m.matchesTableHighlightedIdx == -1 {
+		return ""
+	}
+
+	match := m.matches[m.matchesTableHighlightedIdx]
+
+	if !m.matchDetailsExpanded {
+		return tableStyle.Render(m.matchSummary(match) + "  (x to expand)")
+	}
+
+	details := m.detailsViewport.View()
+
+	if search := m.ViewDetailSearch(); search != "" {
+		details = lipgloss.JoinVertical(lipgloss.Left, details, search)
+	}
+
+	return m.paneStyle(PANE_DETAILS).Render(details)
+}
+
+// _time plus up to three fields, preferring the usual suspects
+func (m Model) matchSummary(match axiomQuery.Entry) string {
+	fields := []string{}
+
+	for _, field := range SUMMARY_MATCH_FIELDS {
+		if _, ok := match.Data[field]; ok && len(fields) < 3 {
+			fields = append(fields, field)
+		}
+	}
+
+	var rest []string = []string{}
+
+	for field := range match.Data {
+		if !stringInSlice(field, fields) {
+			rest = append(rest, field)
+		}
+	}
+
+	sort.Strings(rest)
+
+	for _, field := range rest {
+		if len(fields) >= 3 {
+			break
+		}
+
+		fields = append(fields, field)
+	}
+
+	parts := []string{fmt.Sprintf("_time=%v", m.formatTime(match.Time))}
+
+	for _, field := range fields {
+		parts = append(parts, fmt.Sprintf("%v=%v", field, match.Data[field]))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func (m *Model) ViewSplashScreen() string {
+
+	splashStyle := lipgloss.NewStyle()
+
+	if !m.noColor {
+		splashStyle = splashStyle.Foreground(lipgloss.Color(m.theme.pulseColors[m.pulseStep]))
+	}
 
 	return splashStyle.Render(`
 	█████  ██   ██ ██  ██████  ███    ███ 
@@ -725,29 +2507,251 @@ func (m *Model) ViewSplashScreen() string {
 	██   ██ ██   ██ ██  ██████  ██      ██ `)
 }
 
+func (m *Model) CycleViewMode() tea.Cmd {
+	idx := slices.Index(VIEW_MODES, m.viewMode)
+
+	m.viewMode = VIEW_MODES[(idx+1)%len(VIEW_MODES)]
+
+	return m.SavePrefs(func(prefs *Prefs) {
+		prefs.View = m.viewMode
+	})
+}
+
+func (m *Model) ToggleRawView() {
+	if m.viewMode == "raw" {
+		m.viewMode = VIEW_MODES[0]
+	} else {
+		m.viewMode = "raw"
+	}
+}
+
+func (m *Model) UpdateRawView(result *axiomQuery.Result) {
+	if result == nil {
+		m.rawViewport.SetContent("")
+		return
+	}
+
+	str, _ := json.MarshalIndent(result, "", "  ")
+
+	m.rawViewport.SetContent(colorizeJson(string(str), m.theme.syntax))
+}
+
+func (m *Model) ScrollRawView(msg tea.KeyMsg) tea.Cmd {
+	var cmd tea.Cmd
+
+	switch msg.String() {
+	case "g", "home":
+		m.rawViewport.GotoTop()
+	case "G", "end":
+		m.rawViewport.GotoBottom()
+	default:
+		m.rawViewport, cmd = m.rawViewport.Update(msg)
+	}
+
+	return cmd
+}
+
+func (m Model) ViewRaw() string {
+	if m.query.result == nil {
+		return ""
+	}
+
+	scroll := lipgloss.NewStyle().Foreground(m.theme.hintColor).
+		Render(fmt.Sprintf("%3.f%%", m.rawViewport.ScrollPercent()*100))
+
+	return tableStyle.Render(lipgloss.JoinVertical(
+		lipgloss.Left,
+		m.rawViewport.View(),
+		scroll,
+	))
+}
+
+// the query behind the results, as the textarea may hold the next one by
+// now. Collapsed to one line and cut at the edge of the window.
+func (m Model) ViewRunQuery() string {
+	apl := strings.Join(strings.Fields(m.query.apl), " ")
+
+	if apl == "" {
+		return ""
+	}
+
+	line := []rune("ran: " + apl)
+
+	if room := m.width - 2; m.width > 0 && len(line) > room {
+		line = append(line[:maxInt(room-1, 0)], '…')
+	}
+
+	return lipgloss.NewStyle().PaddingLeft(1).Foreground(m.theme.hintColor).Render(string(line))
+}
+
+func (m Model) ViewResultSummary() string {
+	if m.query.result == nil {
+		return ""
+	}
+
+	summary := fmt.Sprintf("%v matches", len(m.query.result.Matches))
+
+	// raw event queries have no buckets to describe
+	if m.queryMeta != nil {
+		summary += fmt.Sprintf(" · %v groups · %v aggregations · %v intervals",
+			len(m.queryMeta.groups),
+			len(m.queryMeta.ops),
+			m.queryMeta.intervals,
+		)
+	}
+
+	return lipgloss.NewStyle().PaddingLeft(1).Render(summary)
+}
+
+func (m *Model) ViewQueryWarning() string {
+	warnings := []string{}
+
+	for _, warning := range []string{m.queryWarning, m.limitWarning} {
+		if warning != "" {
+			warnings = append(warnings, fmt.Sprintf("Warning: %v", warning))
+		}
+	}
+
+	if len(warnings) == 0 {
+		return ""
+	}
+
+	warningStyle := lipgloss.NewStyle().Foreground(m.theme.warningColor)
+
+	return tableStyle.Render(warningStyle.Render(strings.Join(warnings, "\n")))
+}
+
+// results cut off by the match limit, or marked partial by the server, look
+// complete otherwise
+func (m *Model) UpdateLimitWarning(result *axiomQuery.Result) {
+	m.limitWarning = ""
+
+	if result == nil {
+		return
+	}
+
+	if m.matchLimit > 0 && len(result.Matches) >= m.matchLimit {
+		m.limitWarning = fmt.Sprintf("%v matches is the limit, add a limit or narrow the time range to see everything", len(result.Matches))
+
+		if m.moreCursor != "" {
+			m.limitWarning += ", or press m to load older matches"
+		}
+	} else if result.Status.IsPartial {
+		m.limitWarning = "the result is partial, narrow the time range to see everything"
+	}
+}
+
 func (m *Model) ViewError() string {
 	if m.query.err == nil {
 		return ""
 	}
 
-	return fmt.Sprintf("Error: %v", m.query.err)
+	message := fmt.Sprintf("Error: %v", m.query.err)
+
+	if hint := errorHint(m.query.err); hint != "" {
+		message = lipgloss.JoinVertical(
+			lipgloss.Left,
+			message,
+			lipgloss.NewStyle().Foreground(m.theme.hintColor).Render(hint),
+		)
+	}
+
+	return message
+}
+
+func (m Model) ViewHelp() string {
+	groupStyle := lipgloss.NewStyle().Bold(true).MarginTop(1)
+	keysStyle := lipgloss.NewStyle().Width(20).Foreground(m.theme.accentColor)
+
+	var lines []string = []string{
+		"Key bindings (esc or ? to close)",
+	}
+	var groups []string = []string{}
+
+	for _, binding := range KEY_BINDINGS {
+		if !stringInSlice(binding.group, groups) {
+			groups = append(groups, binding.group)
+		}
+	}
+
+	for _, group := range groups {
+		lines = append(lines, groupStyle.Render(group))
+
+		for _, binding := range KEY_BINDINGS {
+			if binding.group != group {
+				continue
+			}
+
+			lines = append(lines, lipgloss.JoinHorizontal(
+				lipgloss.Left,
+				keysStyle.Render(binding.keys),
+				binding.help,
+			))
+		}
+	}
+
+	return tableStyle.Render(lipgloss.JoinVertical(
+		lipgloss.Left,
+		lines...,
+	))
 }
 
+// the renderer has no bell of its own, the BEL goes out with its frames so
+// it's never written in the middle of one. It takes no room, and as the
+// first line only changes with it the bell rings once.
 func (m Model) View() string {
+	if m.bellRinging {
+		return "\a" + m.viewFrame()
+	}
+
+	return m.viewFrame()
+}
+
+func (m Model) viewFrame() string {
 	if !m.ready {
 		return m.ViewSplashScreen()
 	}
 
+	if m.showHelp {
+		return m.ViewHelp()
+	}
+
 	parts := []string{
-		tableStyle.Render(lipgloss.JoinHorizontal(lipgloss.Left, m.ViewSpinner(), m.ViewRefreshTimeout())),
-		tableStyle.Render(m.textarea.View()),
+		tableStyle.Render(lipgloss.JoinHorizontal(lipgloss.Left, m.ViewSpinner(), m.ViewRefreshTimeout(), m.ViewWatchFile())),
+		m.paneStyle(PANE_TEXTAREA).Render(m.textarea.View()),
 	}
 
+	parts = appendIfNotEmpty(parts, m.ViewCompletions())
+	parts = appendIfNotEmpty(parts, m.ViewHighlightedQuery())
+	parts = appendIfNotEmpty(parts, m.ViewTimeRangePresets())
+	parts = appendIfNotEmpty(parts, m.ViewBookmarks())
+	parts = appendIfNotEmpty(parts, m.ViewMsg())
+	parts = appendIfNotEmpty(parts, m.ViewRunQuery())
+	parts = appendIfNotEmpty(parts, m.ViewResultSummary())
+	parts = appendIfNotEmpty(parts, m.ViewQueryWarning())
 	parts = appendIfNotEmpty(parts, m.ViewError())
-	parts = appendIfNotEmpty(parts, m.ViewGraphs())
-	parts = appendIfNotEmpty(parts, m.ViewTotals())
-	parts = appendIfNotEmpty(parts, m.ViewMatches())
-	parts = appendIfNotEmpty(parts, m.ViewMatchDetails())
+
+	if m.viewMode == "all" || m.viewMode == "graphs" {
+		parts = appendIfNotEmpty(parts, m.ViewHighlightedGroup())
+		parts = appendIfNotEmpty(parts, m.ViewHiddenOps())
+		parts = appendIfNotEmpty(parts, m.ViewOverlay())
+		parts = appendIfNotEmpty(parts, m.ViewGraphs())
+		parts = appendIfNotEmpty(parts, m.ViewTimeAxis())
+		parts = appendIfNotEmpty(parts, m.ViewLegend())
+	}
+
+	if m.viewMode == "all" || m.viewMode == "tables" {
+		parts = appendIfNotEmpty(parts, m.ViewTotals())
+		parts = appendIfNotEmpty(parts, m.ViewFilter())
+		parts = appendIfNotEmpty(parts, m.ViewMatches())
+		parts = appendIfNotEmpty(parts, m.ViewMatchDetails())
+	}
+
+	if m.viewMode == "raw" {
+		parts = appendIfNotEmpty(parts, m.ViewRaw())
+	}
+
+	parts = appendIfNotEmpty(parts, m.ViewStatusBar())
 
 	finalPlot := lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -758,9 +2762,51 @@ func (m Model) View() string {
 }
 
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(tea.EnterAltScreen, func() tea.Msg {
-		return PulseMsg{}
-	}, textarea.Blink)
+	cmds := []tea.Cmd{tea.EnterAltScreen, tea.EnableReportFocus, textarea.Blink}
+
+	// a watched file is run right away, then polled
+	if m.watchFile != "" {
+		cmds = append(cmds, m.PollWatchFile(0))
+	}
+
+	// without the splash there is nothing to pulse
+	if !m.ready {
+		cmds = append(cmds, func() tea.Msg {
+			return PulseMsg{}
+		})
+	}
+
+	return tea.Batch(cmds...)
+}
+
+// values formatted in units when enabled, as is otherwise
+// only used for display, the raw values stay on the query result
+func (m Model) formatOpValue(opName string, value any) string {
+	v := toFloat64(value)
+
+	if str, ok := value.(string); ok {
+		if f, err := strconv.ParseFloat(str, 64); err == nil {
+			v = f
+		}
+	}
+
+	if math.IsNaN(v) {
+		return fmt.Sprintf("%v", value)
+	}
+
+	if m.units && unitRule(opName) != nil {
+		return formatValue(opName, v, m.numbers)
+	}
+
+	return m.formatNumber(v)
+}
+
+func (m Model) formatNumber(v float64) string {
+	return m.numbers.Number(v)
+}
+
+func (m Model) formatTime(t time.Time) string {
+	return formatTime(t, m.timeFormat, m.location)
 }
 
 func (m *Model) setMsg(msg string) {
@@ -780,6 +2826,22 @@ func stringInSlice(str string, slice []string) bool {
 	return false
 }
 
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
 func appendIfNotEmpty(slice []string, str string) []string {
 	if str != "" {
 		slice = append(slice, str)
@@ -788,14 +2850,21 @@ func appendIfNotEmpty(slice []string, str string) []string {
 	return slice
 }
 
-func (m *Model) makeGraphs() []GraphData {
+func (m *Model) makeGraphs(result *axiomQuery.Result) []GraphData {
 	queryMeta := *m.queryMeta
+	graphGroups := m.graphGroups()
+
+	seriesGroups := graphGroups
+
+	if len(graphGroups) < len(queryMeta.groups) {
+		seriesGroups = append(append([]string{}, graphGroups...), OTHER_GROUP)
+	}
 
 	graphs := []GraphData{}
 
 	// each op is a graph
 	for _, op := range queryMeta.ops {
-		data := make([][]float64, len(queryMeta.groups)) // One for each "series"
+		data := make([][]float64, len(seriesGroups)) // One for each "series"
 
 		for i := range data {
 			data[i] = make([]float64, queryMeta.intervals)
@@ -803,36 +2872,261 @@ func (m *Model) makeGraphs() []GraphData {
 
 		seriesColors := []asciigraph.AnsiColor{}
 
-		for _, group := range queryMeta.groups {
+		for i, group := range seriesGroups {
 			color := queryMeta.groupColors[group]
 
-			if m.highlightedGroup != "" && group != m.highlightedGroup {
-				color = asciigraph.SlateGray
+			if i >= len(graphGroups) {
+				color = m.theme.otherColor
+			}
+
+			if m.groupDimmed(group) {
+				color = m.theme.dimColor
 			}
 
 			seriesColors = append(seriesColors, color)
 		}
 
+		title := op.name
+
+		if m.highlightedGroup != "" {
+			title = fmt.Sprintf("%v · %v: %v", op.name, m.highlightedGroup, highlightedTotal(result, queryMeta.orderedGroupKeys, m.highlightedGroup, op.name, func(value any) string {
+				return m.formatOpValue(op.name, value)
+			}))
+		}
+
+		threshold, hasThreshold := m.thresholds[op.name]
+
 		graphs = append(graphs, GraphData{
-			title:  op.name,
-			data:   data,
-			colors: seriesColors,
+			title:          title,
+			op:             op.name,
+			groups:         seriesGroups,
+			labels:         append([]string{}, seriesGroups...),
+			data:           data,
+			colors:         seriesColors,
+			threshold:      threshold,
+			hasThreshold:   hasThreshold,
+			thresholdColor: m.theme.thresholdColor,
 		})
 	}
 
 	return graphs
 }
 
+// sort numerically when the whole column holds numbers, lexically otherwise
+func sortRows(rows []table.Row, col int, asc bool) {
+	numeric := true
+
+	for _, row := range rows {
+		if _, err := strconv.ParseFloat(row[col], 64); err != nil {
+			numeric = false
+			break
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i][col], rows[j][col]
+
+		if numeric {
+			af, _ := strconv.ParseFloat(a, 64)
+			bf, _ := strconv.ParseFloat(b, 64)
+
+			if asc {
+				return af < bf
+			}
+
+			return af > bf
+		}
+
+		if asc {
+			return a < b
+		}
+
+		return a > b
+	})
+}
+
+func getDatasetField(matches []axiomQuery.Entry) string {
+	for _, field := range DATASET_FIELDS {
+		for _, match := range matches {
+			if _, ok := match.Data[field]; ok {
+				return field
+			}
+		}
+	}
+
+	return ""
+}
+
+// case-insensitive substring match against the time and every field
+func filterMatches(matches []axiomQuery.Entry, filter string, formatTime func(time.Time) string) []axiomQuery.Entry {
+	if filter == "" {
+		return matches
+	}
+
+	filter = strings.ToLower(filter)
+	filtered := []axiomQuery.Entry{}
+
+	for _, match := range matches {
+		if strings.Contains(strings.ToLower(formatTime(match.Time)), filter) {
+			filtered = append(filtered, match)
+			continue
+		}
+
+		for _, value := range match.Data {
+			if strings.Contains(strings.ToLower(fmt.Sprintf("%v", value)), filter) {
+				filtered = append(filtered, match)
+				break
+			}
+		}
+	}
+
+	return filtered
+}
+
+// groups by their total for the first op, largest first
+func rankGroups(result *axiomQuery.Result, orderedGroupKeys []string, groups []string, ops []Op) []string {
+	ranked := append([]string{}, groups...)
+
+	if len(ops) == 0 {
+		return ranked
+	}
+
+	totals := map[string]float64{}
+
+	for _, total := range result.Buckets.Totals {
+		for _, aggregation := range total.Aggregations {
+			if aggregation.Alias == ops[0].name {
+				totals[getGroupKey(orderedGroupKeys, total.Group)] = toFloat64(aggregation.Value)
+			}
+		}
+	}
+
+	value := func(group string) float64 {
+		v, ok := totals[group]
+
+		if !ok || math.IsNaN(v) {
+			return math.Inf(-1)
+		}
+
+		return v
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return value(ranked[i]) > value(ranked[j])
+	})
+
+	return ranked
+}
+
+// one value per interval, NaN where the group has no value for op
+func groupSeries(result *axiomQuery.Result, orderedGroupKeys []string, groupKey string, opName string) []float64 {
+	values := make([]float64, len(result.Buckets.Series))
+
+	for intervalIdx, interval := range result.Buckets.Series {
+		values[intervalIdx] = math.NaN()
+
+		for _, group := range interval.Groups {
+			if getGroupKey(orderedGroupKeys, group.Group) != groupKey {
+				continue
+			}
+
+			for _, aggregation := range group.Aggregations {
+				if aggregation.Alias == opName {
+					values[intervalIdx] = toFloat64(aggregation.Value)
+				}
+			}
+		}
+	}
+
+	return values
+}
+
+// the highlighted group's value for op in the totals, if there is one
+func highlightedTotal(result *axiomQuery.Result, orderedGroupKeys []string, groupKey string, opName string, formatOpValue func(any) string) string {
+	for _, total := range result.Buckets.Totals {
+		if getGroupKey(orderedGroupKeys, total.Group) != groupKey {
+			continue
+		}
+
+		for _, aggregation := range total.Aggregations {
+			if aggregation.Alias == opName {
+				return formatOpValue(aggregation.Value)
+			}
+		}
+	}
+
+	return "no total"
+}
+
+// keys a group doesn't have are padded as empty
+func groupValue(group map[string]interface{}, key string) string {
+	value, ok := group[key]
+
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprintf("%v", value)
+}
+
 func getGroupKey(orderedGroupKeys []string, group map[string]interface{}) string {
 	var keyVals []string = []string{}
 
 	for _, k := range orderedGroupKeys {
-		keyVals = append(keyVals, fmt.Sprintf("%v", group[k]))
+		keyVals = append(keyVals, groupValue(group, k))
 	}
 
 	return strings.Join(keyVals, ", ")
 }
 
+// convert any numeric aggregation value to a float64, NaN otherwise
+func toFloat64(value any) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+	}
+
+	return math.NaN()
+}
+
+// each group starts from its hashed color and moves on to the next unused one,
+// colors are only reused once the palette is exhausted. groups are sorted so
+// the same group set always gets the same colors
+func assignGroupColors(groups []string, palette []asciigraph.AnsiColor) map[string]asciigraph.AnsiColor {
+	groupColors := map[string]asciigraph.AnsiColor{}
+	used := map[int]bool{}
+
+	for _, group := range groups {
+		if len(used) == len(palette) {
+			used = map[int]bool{}
+		}
+
+		colorIdx := hash(group) % len(palette)
+
+		for used[colorIdx] {
+			colorIdx = (colorIdx + 1) % len(palette)
+		}
+
+		used[colorIdx] = true
+		groupColors[group] = palette[colorIdx]
+	}
+
+	return groupColors
+}
+
+// asciigraph colors are xterm 256 color codes, which lipgloss accepts as strings
+func ansiColor(color asciigraph.AnsiColor) lipgloss.Color {
+	return lipgloss.Color(fmt.Sprintf("%d", color))
+}
+
 func hash(s string) int {
 	h := fnv.New32a()
 	h.Write([]byte(s))
@@ -840,3 +3134,38 @@ func hash(s string) int {
 
 	return int(sum)
 }
+
+const MAX_SPARKLINE_WIDTH = 30
+
+var SPARKLINE_BLOCKS = []rune("▁▂▃▄▅▆▇█")
+
+// scale values onto the eight block glyphs, gaps are left blank
+func sparkline(values []float64) string {
+	low, high := math.Inf(1), math.Inf(-1)
+
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			low = math.Min(low, v)
+			high = math.Max(high, v)
+		}
+	}
+
+	var b strings.Builder
+
+	for _, v := range values {
+		if math.IsNaN(v) {
+			b.WriteRune(' ')
+			continue
+		}
+
+		idx := 0
+
+		if high > low {
+			idx = int((v - low) / (high - low) * float64(len(SPARKLINE_BLOCKS)-1))
+		}
+
+		b.WriteRune(SPARKLINE_BLOCKS[idx])
+	}
+
+	return b.String()
+}
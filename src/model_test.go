@@ -0,0 +1,367 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	axiomQuery "github.com/axiomhq/axiom-go/axiom/query"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	asciigraph "github.com/guptarohit/asciigraph"
+)
+
+func TestFitPlotLeavesDataAlone(t *testing.T) {
+	data := [][]float64{{1, 2, 4, 3}, {40, 35, 30, 32.5}}
+	want := [][]float64{{1, 2, 4, 3}, {40, 35, 30, 32.5}}
+
+	// narrow enough to be plotted twice
+	fitPlot(data, []asciigraph.Option{asciigraph.Height(5)}, 20)
+
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("fitPlot changed the data to %v, want %v", data, want)
+	}
+}
+
+// the first query resolves after the second one, which it was superseded by
+func TestStaleResultIgnored(t *testing.T) {
+	first, second := "['logs'] | limit 1", "['logs'] | limit 2"
+	client := &fakeQuerier{results: map[string]*axiomQuery.Result{
+		first:  {Matches: testMatches(1)},
+		second: {Matches: testMatches(2)},
+	}}
+
+	m := testModel(client)
+
+	firstCmd := m.RunQuery(first)
+	secondCmd := m.RunQuery(second)
+
+	secondMsg, firstMsg := queryResult(t, secondCmd), queryResult(t, firstCmd)
+
+	if firstMsg.gen >= secondMsg.gen {
+		t.Fatalf("first query stamped %v, second %v", firstMsg.gen, secondMsg.gen)
+	}
+
+	next, _ := m.Update(secondMsg)
+	next, _ = next.(Model).Update(firstMsg)
+	m = next.(Model)
+
+	if m.query.apl != second || m.query.err != nil {
+		t.Errorf("showing %q (error %v), want the result of %q", m.query.apl, m.query.err, second)
+	}
+
+	if m.matchesTable == nil || len(m.matchesTable.Rows()) != 2 {
+		t.Errorf("want the 2 matches of the second query")
+	}
+}
+
+func seriesOf(t *testing.T, graph GraphData, group string) []float64 {
+	t.Helper()
+
+	for i, g := range graph.groups {
+		if g == group {
+			return graph.data[i]
+		}
+	}
+
+	t.Fatalf("no %v series in %v, only %v", group, graph.op, graph.groups)
+	return nil
+}
+
+// a group the meta doesn't know about sorts in between the known ones, where
+// a binary search alone would put its values in the next group's series
+func TestUnknownGroupNotGraphed(t *testing.T) {
+	get := map[string]any{"method": "GET"}
+	post := map[string]any{"method": "POST"}
+	patch := map[string]any{"method": "PATCH"}
+
+	known := &axiomQuery.Result{Buckets: axiomQuery.Timeseries{Series: testSeries(
+		[]axiomQuery.EntryGroup{testGroup(get, 1), testGroup(post, 2)},
+		[]axiomQuery.EntryGroup{testGroup(get, 3), testGroup(post, 4)},
+	)}}
+	withUnknown := &axiomQuery.Result{Buckets: axiomQuery.Timeseries{Series: testSeries(
+		[]axiomQuery.EntryGroup{testGroup(get, 1), testGroup(patch, 100), testGroup(post, 2)},
+		[]axiomQuery.EntryGroup{testGroup(get, 3), testGroup(post, 4), testGroup(patch, 200)},
+	)}}
+
+	m := testModel(nil)
+	m.UpdateQueryMeta(known)
+	m.UpdateGraphs(withUnknown)
+
+	graph := (*m.graphs)[0]
+
+	if len(graph.groups) != 2 {
+		t.Fatalf("graphed %v, want only GET and POST", graph.groups)
+	}
+
+	if got := seriesOf(t, graph, "GET"); !reflect.DeepEqual(got, []float64{1, 3}) {
+		t.Errorf("GET is %v, want [1 3]", got)
+	}
+
+	if got := seriesOf(t, graph, "POST"); !reflect.DeepEqual(got, []float64{2, 4}) {
+		t.Errorf("POST is %v, want [2 4]", got)
+	}
+}
+
+// counts don't always come back as float64, depending on how they're decoded
+func TestMixedNumericValuesGraphed(t *testing.T) {
+	get := map[string]any{"method": "GET"}
+
+	result := &axiomQuery.Result{Buckets: axiomQuery.Timeseries{Series: testSeries(
+		[]axiomQuery.EntryGroup{testGroup(get, 1)},
+		[]axiomQuery.EntryGroup{testGroup(get, int64(2))},
+		[]axiomQuery.EntryGroup{testGroup(get, json.Number("3"))},
+		[]axiomQuery.EntryGroup{testGroup(get, 4.5)},
+		[]axiomQuery.EntryGroup{testGroup(get, "n/a")},
+	)}}
+
+	m := testModel(nil)
+	m.UpdateQueryMeta(result)
+	m.UpdateGraphs(result)
+
+	got := seriesOf(t, (*m.graphs)[0], "GET")
+	want := []float64{1, 2, 3, 4.5}
+
+	for i, v := range want {
+		if got[i] != v {
+			t.Errorf("interval %v is %v, want %v", i, got[i], v)
+		}
+	}
+
+	if !math.IsNaN(got[4]) {
+		t.Errorf("a string is graphed as %v, want NaN", got[4])
+	}
+}
+
+func TestGroupColorsDistinct(t *testing.T) {
+	palette := THEMES["mono"].colors
+	groups := []string{}
+
+	for i := 0; i < len(palette)*3; i++ {
+		groups = append(groups, fmt.Sprintf("group-%02d", i))
+	}
+
+	colors := assignGroupColors(groups, palette)
+	seen := map[asciigraph.AnsiColor]string{}
+
+	for _, group := range groups[:len(palette)] {
+		if other, ok := seen[colors[group]]; ok {
+			t.Errorf("%v and %v are both %v", other, group, colors[group])
+		}
+
+		seen[colors[group]] = group
+	}
+
+	if again := assignGroupColors(groups, palette); !reflect.DeepEqual(again, colors) {
+		t.Errorf("the same groups got other colors the second time")
+	}
+}
+
+func TestOtherSumsExcludedGroups(t *testing.T) {
+	group := func(method string, value any) axiomQuery.EntryGroup {
+		return testGroup(map[string]any{"method": method}, value)
+	}
+
+	result := &axiomQuery.Result{Buckets: axiomQuery.Timeseries{
+		Series: testSeries(
+			[]axiomQuery.EntryGroup{group("GET", 50), group("POST", 40), group("PUT", 3), group("DELETE", 1)},
+			[]axiomQuery.EntryGroup{group("GET", 60), group("POST", 30), group("PUT", 5)},
+			[]axiomQuery.EntryGroup{group("GET", 70), group("POST", 20), group("PUT", "n/a"), group("DELETE", 2)},
+		),
+		Totals: []axiomQuery.EntryGroup{group("GET", 180), group("POST", 90), group("PUT", 8), group("DELETE", 3)},
+	}}
+
+	m := testModel(nil)
+	m.topN = 2
+	m.UpdateQueryMeta(result)
+	m.UpdateGraphs(result)
+
+	graph := (*m.graphs)[0]
+
+	if !reflect.DeepEqual(graph.groups, []string{"GET", "POST", OTHER_GROUP}) {
+		t.Fatalf("graphed %v, want the top 2 and %v", graph.groups, OTHER_GROUP)
+	}
+
+	if got := seriesOf(t, graph, OTHER_GROUP); !reflect.DeepEqual(got, []float64{4, 5, 2}) {
+		t.Errorf("%v is %v, want PUT and DELETE summed as [4 5 2]", OTHER_GROUP, got)
+	}
+}
+
+func TestGraphCellsFitWidth(t *testing.T) {
+	m := withResult(testModel(nil), "['logs'] | summarize count(), avg(duration) by bin(_time, 1m), method", groupedResult())
+
+	// the cell border and the padding around the graphs
+	for width := 5; width <= 200; width++ {
+		m.width = width
+
+		perRow, cellWidth := m.graphLayout(2)
+
+		if used := perRow*(cellWidth+2) + 2; used > width {
+			t.Errorf("%v cells of %v take %v columns of %v", perRow, cellWidth, used, width)
+		}
+
+		for _, line := range strings.Split(m.ViewGraphs(), "\n") {
+			if lipgloss.Width(line) > width {
+				t.Errorf("a graph line is %v wide in %v columns", lipgloss.Width(line), width)
+				break
+			}
+		}
+	}
+}
+
+// a group with nothing but non-numbers for an op is listed as without data
+// rather than plotted flat along 0
+func TestEmptySeriesDropped(t *testing.T) {
+	get := map[string]any{"method": "GET"}
+	post := map[string]any{"method": "POST"}
+
+	result := &axiomQuery.Result{Buckets: axiomQuery.Timeseries{Series: testSeries(
+		[]axiomQuery.EntryGroup{testGroup(get, 1, nil), testGroup(post, nil, 10.0)},
+		[]axiomQuery.EntryGroup{testGroup(get, 2, nil)},
+	)}}
+
+	m := testModel(nil)
+	m.UpdateQueryMeta(result)
+	m.UpdateGraphs(result)
+
+	count, duration := (*m.graphs)[0], (*m.graphs)[1]
+
+	if !reflect.DeepEqual(count.groups, []string{"GET"}) || !reflect.DeepEqual(count.noData, []string{"POST"}) {
+		t.Errorf("%v graphs %v without data for %v, want GET and POST without", count.op, count.groups, count.noData)
+	}
+
+	if !reflect.DeepEqual(duration.groups, []string{"POST"}) || !reflect.DeepEqual(duration.noData, []string{"GET"}) {
+		t.Errorf("%v graphs %v without data for %v, want POST and GET without", duration.op, duration.groups, duration.noData)
+	}
+
+	if len(count.data) != len(count.groups) || len(count.colors) != len(count.groups) || len(count.labels) != len(count.groups) {
+		t.Errorf("the series of %v don't line up with its groups", count.op)
+	}
+}
+
+func TestOpsFromSeriesWithoutTotals(t *testing.T) {
+	result := groupedResult()
+	result.Buckets.Totals = nil
+
+	m := testModel(nil)
+	m.UpdateQueryMeta(result)
+	m.UpdateGraphs(result)
+
+	if want := []Op{{name: "count_"}, {name: "avg_duration"}}; !reflect.DeepEqual(m.queryMeta.ops, want) {
+		t.Errorf("ops %v, want %v", m.queryMeta.ops, want)
+	}
+
+	if m.graphs == nil || len(*m.graphs) != 2 {
+		t.Errorf("want a graph per op")
+	}
+}
+
+// groups of later intervals and the totals can be keyed by more fields
+func TestGroupKeysUnion(t *testing.T) {
+	result := &axiomQuery.Result{Buckets: axiomQuery.Timeseries{
+		Series: testSeries(
+			[]axiomQuery.EntryGroup{testGroup(map[string]any{"method": "GET"}, 1)},
+			[]axiomQuery.EntryGroup{testGroup(map[string]any{"region": "eu", "method": "POST"}, 2)},
+		),
+		Totals: []axiomQuery.EntryGroup{testGroup(map[string]any{"method": "PUT", "zone": "a"}, 3)},
+	}}
+
+	m := testModel(nil)
+	m.UpdateQueryMeta(result)
+
+	if want := []string{"method", "region", "zone"}; !reflect.DeepEqual(m.queryMeta.orderedGroupKeys, want) {
+		t.Errorf("keys %v, want %v", m.queryMeta.orderedGroupKeys, want)
+	}
+
+	if want := []string{"GET, , ", "POST, eu, "}; !reflect.DeepEqual(m.queryMeta.groups, want) {
+		t.Errorf("groups %q, want %q", m.queryMeta.groups, want)
+	}
+}
+
+func TestGraphRows(t *testing.T) {
+	tests := []struct {
+		width int
+		count int
+		rows  int
+	}{
+		{60, 3, 3},
+		{100, 1, 1},
+		{100, 3, 2},
+		{100, 4, 2},
+		{200, 5, 2},
+		{200, 8, 2},
+		{200, 9, 3},
+	}
+
+	for _, test := range tests {
+		m := Model{width: test.width}
+		perRow, _ := m.graphLayout(test.count)
+
+		if got := graphRowsCount(test.count, perRow); got != test.rows {
+			t.Errorf("%v graphs in %v columns take %v rows, want %v", test.count, test.width, got, test.rows)
+		}
+	}
+}
+
+func TestPulseStepInBounds(t *testing.T) {
+	for name, theme := range THEMES {
+		config := testConfig()
+		config.theme = theme
+		config.noSplash = false
+
+		m := newModel(config, nil)
+
+		for i := 0; i < len(theme.pulseColors)*5; i++ {
+			m.UpdatePulse()
+
+			if m.pulseStep < 0 || m.pulseStep >= len(theme.pulseColors) {
+				t.Fatalf("%v: pulse step %v after %v updates, past its %v colors", name, m.pulseStep, i+1, len(theme.pulseColors))
+			}
+		}
+
+		// a key press lets the cycle run out, then the query is shown
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+		m = next.(Model)
+
+		for i := 0; i < len(theme.pulseColors) && !m.ready; i++ {
+			m.UpdatePulse()
+		}
+
+		if !m.ready {
+			t.Errorf("%v: still on the splash a full cycle after a key press", name)
+		}
+	}
+}
+
+// every point of every series comes from the result, see also the grouped
+// golden file for how they're drawn
+func TestGraphsFromResult(t *testing.T) {
+	m := testModel(nil)
+	result := groupedResult()
+	m.UpdateQueryMeta(result)
+	m.UpdateGraphs(result)
+
+	want := map[string]map[string][]float64{
+		"count_":       {"GET": {3, 5, 4, 6}, "POST": {1, 2, 4, 3}},
+		"avg_duration": {"GET": {12.5, 10, 11, 9.5}, "POST": {40, 35, 30, 32.5}},
+	}
+
+	if len(*m.graphs) != len(want) {
+		t.Fatalf("%v graphs, want %v", len(*m.graphs), len(want))
+	}
+
+	for _, graph := range *m.graphs {
+		for group, series := range want[graph.op] {
+			if got := seriesOf(t, graph, group); !reflect.DeepEqual(got, series) {
+				t.Errorf("%v of %v is %v, want %v", graph.op, group, got, series)
+			}
+		}
+
+		if graph.colors[0] == graph.colors[1] {
+			t.Errorf("GET and POST share a color on %v", graph.op)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	axiomQuery "github.com/axiomhq/axiom-go/axiom/query"
+)
+
+// --query runs once and prints the result instead of starting the TUI,
+// the exit code tells scripts whether it worked
+func runOnce(config Config) int {
+	apl, err := substituteVars(config.query, config.vars)
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	result, err := newQuerier(config).Query(ctx, apl)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+
+		if hint := errorHint(err); hint != "" {
+			fmt.Fprintln(os.Stderr, hint)
+		}
+
+		return 1
+	}
+
+	switch config.format {
+	case "json":
+		err = writeJson(os.Stdout, result)
+	case "csv":
+		err = writeCsv(os.Stdout, result, config)
+	default:
+		err = writeTables(os.Stdout, result, config)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
+
+func writeJson(w io.Writer, result *axiomQuery.Result) error {
+	data, err := json.MarshalIndent(result, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(data))
+
+	return err
+}
+
+// group keys then one column per aggregation, a row per total
+func totalsRecords(result *axiomQuery.Result) ([]string, [][]string) {
+	keys := []string{}
+	ops := []string{}
+
+	for _, total := range result.Buckets.Totals {
+		for key := range total.Group {
+			if !stringInSlice(key, keys) {
+				keys = append(keys, key)
+			}
+		}
+
+		for _, aggregation := range total.Aggregations {
+			if !stringInSlice(aggregation.Alias, ops) {
+				ops = append(ops, aggregation.Alias)
+			}
+		}
+	}
+
+	sort.Strings(keys)
+
+	rows := [][]string{}
+
+	for _, total := range result.Buckets.Totals {
+		row := []string{}
+
+		for _, key := range keys {
+			row = append(row, groupValue(total.Group, key))
+		}
+
+		values := map[string]string{}
+
+		for _, aggregation := range total.Aggregations {
+			values[aggregation.Alias] = fmt.Sprintf("%v", aggregation.Value)
+		}
+
+		for _, op := range ops {
+			row = append(row, values[op])
+		}
+
+		rows = append(rows, row)
+	}
+
+	return append(keys, ops...), rows
+}
+
+// the same columns the matches table shows
+func matchesRecords(result *axiomQuery.Result, config Config) ([]string, [][]string) {
+	fields := matchColumnFields(result.Matches, config.matchColumns, getDatasetField(result.Matches))
+
+	rows := [][]string{}
+
+	for _, match := range result.Matches {
+		row := []string{formatTime(match.Time, config.timeFormat, config.location)}
+
+		for _, field := range fields {
+			value, ok := match.Data[field]
+
+			if !ok || value == nil {
+				row = append(row, "")
+			} else {
+				row = append(row, fmt.Sprintf("%v", value))
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return append([]string{"_time"}, fields...), rows
+}
+
+// plain text, aligned with tabs
+func writeTables(w io.Writer, result *axiomQuery.Result, config Config) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	writeRecords := func(header []string, rows [][]string) {
+		fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+		for _, row := range rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+	}
+
+	if len(result.Buckets.Totals) > 0 {
+		writeRecords(totalsRecords(result))
+	}
+
+	if len(result.Matches) > 0 {
+		if len(result.Buckets.Totals) > 0 {
+			fmt.Fprintln(tw)
+		}
+
+		writeRecords(matchesRecords(result, config))
+	}
+
+	return tw.Flush()
+}
+
+// a single table, the totals of an aggregating query and the matches otherwise
+func writeCsv(w io.Writer, result *axiomQuery.Result, config Config) error {
+	header, rows := matchesRecords(result, config)
+
+	if len(result.Buckets.Totals) > 0 {
+		header, rows = totalsRecords(result)
+	}
+
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+
+	return cw.Error()
+}
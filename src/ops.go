@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// hidden by name, so an op stays hidden across refreshes while the query
+// keeps asking for it
+func (m *Model) ToggleOp(idx int) {
+	if m.queryMeta == nil || idx >= len(m.queryMeta.ops) {
+		return
+	}
+
+	name := m.queryMeta.ops[idx].name
+
+	if m.hiddenOps[name] {
+		delete(m.hiddenOps, name)
+		m.setMsg(fmt.Sprintf("Showing %v", name))
+	} else {
+		m.hiddenOps[name] = true
+		m.setMsg(fmt.Sprintf("Hiding %v", name))
+	}
+}
+
+// the graphs as drawn, the shown ops overlaid on one when asked to
+func (m Model) visibleGraphs() []GraphData {
+	graphs := m.shownOpGraphs()
+
+	if overlay, _ := m.overlaid(graphs); overlay != nil {
+		return []GraphData{*overlay}
+	}
+
+	return graphs
+}
+
+func (m Model) shownOpGraphs() []GraphData {
+	graphs := []GraphData{}
+
+	if m.graphs == nil {
+		return graphs
+	}
+
+	for _, graph := range *m.graphs {
+		if !m.hiddenOps[graph.op] {
+			graphs = append(graphs, graph)
+		}
+	}
+
+	return graphs
+}
+
+// only shown while an op of this result is hidden, with the key to bring it back
+func (m Model) ViewHiddenOps() string {
+	if m.graphs == nil || m.queryMeta == nil {
+		return ""
+	}
+
+	hidden := []string{}
+
+	for i, op := range m.queryMeta.ops {
+		if m.hiddenOps[op.name] {
+			hidden = append(hidden, fmt.Sprintf("%v %v", i+1, op.name))
+		}
+	}
+
+	if len(hidden) == 0 {
+		return ""
+	}
+
+	return lipgloss.NewStyle().
+		PaddingLeft(1).
+		Foreground(m.theme.hintColor).
+		Render(fmt.Sprintf("Hidden: %v", strings.Join(hidden, " · ")))
+}
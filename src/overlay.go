@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// past this ratio between the peaks of two ops the smaller one would be a
+// flat line along the bottom of the overlay
+const MAX_OVERLAY_SCALE = 100
+
+func (m *Model) ToggleOverlay() {
+	m.overlayOps = !m.overlayOps
+
+	if m.overlayOps {
+		m.setMsg("Overlaying the shown ops on one graph")
+	} else {
+		m.setMsg("One graph per op")
+	}
+}
+
+func peak(graph GraphData) float64 {
+	highest := 0.0
+
+	for _, series := range graph.data {
+		for _, v := range series {
+			if !math.IsNaN(v) {
+				highest = math.Max(highest, math.Abs(v))
+			}
+		}
+	}
+
+	return highest
+}
+
+// ops only share a graph when they're formatted in the same unit and are of
+// a similar size, why not otherwise
+func overlayProblem(graphs []GraphData) string {
+	lowest, highest := graphs[0], graphs[0]
+
+	for _, graph := range graphs[1:] {
+		if unitRule(graph.op) != unitRule(graphs[0].op) {
+			return fmt.Sprintf("%v and %v don't share a unit", graphs[0].op, graph.op)
+		}
+
+		if peak(graph) < peak(lowest) {
+			lowest = graph
+		}
+
+		if peak(graph) > peak(highest) {
+			highest = graph
+		}
+	}
+
+	if peak(lowest) > 0 && peak(highest)/peak(lowest) > MAX_OVERLAY_SCALE {
+		return fmt.Sprintf("%v is over %vx %v", highest.op, MAX_OVERLAY_SCALE, lowest.op)
+	}
+
+	return ""
+}
+
+// every series of every op in one graph, each in its own color unless it's
+// dimmed in its own graph
+func (m Model) overlayGraph(graphs []GraphData) GraphData {
+	ops := []string{}
+	overlay := GraphData{op: graphs[0].op}
+
+	for _, graph := range graphs {
+		ops = append(ops, graph.op)
+
+		for i, group := range graph.groups {
+			overlay.groups = append(overlay.groups, group)
+			overlay.labels = append(overlay.labels, fmt.Sprintf("%v · %v", graph.labels[i], graph.op))
+			overlay.data = append(overlay.data, graph.data[i])
+		}
+	}
+
+	colors := assignGroupColors(overlay.labels, m.theme.colors)
+	idx := 0
+
+	for _, graph := range graphs {
+		for _, color := range graph.colors {
+			if color != m.theme.dimColor {
+				color = colors[overlay.labels[idx]]
+			}
+
+			overlay.colors = append(overlay.colors, color)
+			idx += 1
+		}
+	}
+
+	overlay.title = strings.Join(ops, " + ")
+
+	return overlay
+}
+
+// the shown ops overlaid when they can be, nil when they're graphed each on
+// their own
+func (m Model) overlaid(graphs []GraphData) (*GraphData, string) {
+	if !m.overlayOps || len(graphs) < 2 {
+		return nil, ""
+	}
+
+	if problem := overlayProblem(graphs); problem != "" {
+		return nil, problem
+	}
+
+	overlay := m.overlayGraph(graphs)
+
+	return &overlay, ""
+}
+
+func (m Model) ViewOverlay() string {
+	if !m.overlayOps || m.graphs == nil {
+		return ""
+	}
+
+	graphs := m.shownOpGraphs()
+	label := "Overlaid, o to graph each op on its own"
+
+	if len(graphs) < 2 {
+		label = "Nothing to overlay, only one op is shown"
+	} else if _, problem := m.overlaid(graphs); problem != "" {
+		label = fmt.Sprintf("Not overlaid, %v", problem)
+	}
+
+	return lipgloss.NewStyle().
+		PaddingLeft(1).
+		Foreground(m.theme.hintColor).
+		Render(label)
+}
+
+// one entry per series, as the group colors don't hold in the overlay
+func (m Model) ViewOverlayLegend(overlay GraphData) string {
+	entries := []string{}
+
+	for i, label := range overlay.labels {
+		entries = append(entries, lipgloss.NewStyle().
+			Foreground(ansiColor(overlay.colors[i])).
+			PaddingRight(2).
+			Render("■ "+label))
+	}
+
+	entries = appendIfNotEmpty(entries, m.ViewPreviousLegend())
+
+	return tableStyle.Render(lipgloss.JoinHorizontal(
+		lipgloss.Left,
+		entries...,
+	))
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	axiomQuery "github.com/axiomhq/axiom-go/axiom/query"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type MoreMatchesMsg struct {
+	gen    int
+	apl    string
+	cursor string
+	result *axiomQuery.Result
+	err    error
+}
+
+// matches come newest first, so the next page starts at the oldest row the
+// server saw. Aggregations don't page.
+func (m Model) pageCursor(result *axiomQuery.Result) string {
+	if _, ok := m.client.(Pager); !ok || result == nil || len(result.Matches) == 0 || len(result.Buckets.Totals) > 0 {
+		return ""
+	}
+
+	return result.Status.MinCursor
+}
+
+// with a context of its own, as the query's is done with by the time there's
+// a page to load. Canceled when a new query starts or the query is canceled.
+func (m *Model) LoadMoreMatches() tea.Cmd {
+	pager, ok := m.client.(Pager)
+
+	if !ok || m.moreCursor == "" {
+		m.setMsg("No more matches to load")
+		return nil
+	}
+
+	if m.loadingMore {
+		return nil
+	}
+
+	m.loadingMore = true
+	m.setMsg("Loading more matches...")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelPage = cancel
+
+	gen, apl, cursor := m.queryGen, m.query.apl, m.moreCursor
+
+	return func() tea.Msg {
+		res, err := pager.QueryPage(ctx, apl, cursor)
+
+		return MoreMatchesMsg{gen: gen, apl: apl, cursor: cursor, result: res, err: err}
+	}
+}
+
+func (m *Model) CancelLoadMore() {
+	if m.cancelPage != nil {
+		m.cancelPage()
+		m.cancelPage = nil
+	}
+
+	m.loadingMore = false
+}
+
+// older matches go below the ones already loaded, skipping any seen before
+func appendOlderMatches(seen []axiomQuery.Entry, older []axiomQuery.Entry) ([]axiomQuery.Entry, int) {
+	keys := map[uint64]bool{}
+
+	for _, match := range seen {
+		keys[matchKey(match)] = true
+	}
+
+	merged := append([]axiomQuery.Entry{}, seen...)
+
+	for _, match := range older {
+		key := matchKey(match)
+
+		if keys[key] {
+			continue
+		}
+
+		keys[key] = true
+		merged = append(merged, match)
+	}
+
+	return merged, len(merged) - len(seen)
+}
+
+// a page for a query that's been re-run since is dropped, the cursor it
+// came from is gone. So is one canceled along with its query, by then
+// another page may be loading.
+func (m *Model) AppendMatches(msg MoreMatchesMsg) {
+	if msg.gen != m.queryGen {
+		return
+	}
+
+	m.CancelLoadMore()
+
+	if msg.apl != m.query.apl || msg.cursor != m.moreCursor || m.query.result == nil {
+		return
+	}
+
+	if msg.err != nil {
+		m.setMsg(fmt.Sprintf("Could not load more matches: %v", msg.err))
+		return
+	}
+
+	result := *m.query.result
+
+	var added int
+	result.Matches, added = appendOlderMatches(result.Matches, msg.result.Matches)
+	m.query.result = &result
+
+	// a page without anything new is the end, and the warning about the
+	// first page being cut off is out of date either way
+	m.moreCursor = ""
+	m.limitWarning = ""
+
+	if added == 0 {
+		m.setMsg("No older matches")
+		return
+	}
+
+	m.moreCursor = m.pageCursor(msg.result)
+
+	if m.moreCursor != "" {
+		m.limitWarning = fmt.Sprintf("%v matches loaded, press m to load older ones", len(result.Matches))
+	}
+
+	selection := m.saveSelection()
+
+	m.UpdateMatchesTable(m.query.result)
+	m.UpdateRawView(m.query.result)
+	m.restoreSelection(selection)
+
+	m.setMsg(fmt.Sprintf("Loaded %v more matches", added))
+}
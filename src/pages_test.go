@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	axiomQuery "github.com/axiomhq/axiom-go/axiom/query"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// holds on to every page until it's canceled
+type blockingPager struct {
+	fakeQuerier
+}
+
+func (q *blockingPager) QueryPage(ctx context.Context, apl string, cursor string) (*axiomQuery.Result, error) {
+	<-ctx.Done()
+
+	return nil, ctx.Err()
+}
+
+func pressKey(m Model, key string) (Model, tea.Cmd) {
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+
+	return next.(Model), cmd
+}
+
+func TestLoadMoreCanceledByNewQuery(t *testing.T) {
+	apl := "['logs'] | limit 5"
+
+	tests := []struct {
+		name     string
+		newQuery func(m Model) Model
+	}{
+		{"refreshed", func(m Model) Model {
+			m, _ = pressKey(m, "r")
+			return m
+		}},
+		{"typed", func(m Model) Model {
+			next, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+			next, _ = next.(Model).Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+			return next.(Model)
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := &axiomQuery.Result{Matches: testMatches(5)}
+			result.Status.MinCursor = "cursor"
+
+			client := &blockingPager{fakeQuerier{results: map[string]*axiomQuery.Result{apl: result}}}
+			m := runTyped(t, testModel(client), apl)
+
+			m, cmd := pressKey(m, "m")
+
+			if !m.loadingMore || cmd == nil {
+				t.Fatalf("m didn't start loading more matches")
+			}
+
+			pages := make(chan tea.Msg, 1)
+			go func() { pages <- cmd() }()
+
+			m = test.newQuery(m)
+
+			var page MoreMatchesMsg
+
+			select {
+			case msg := <-pages:
+				page = msg.(MoreMatchesMsg)
+			case <-time.After(time.Second):
+				t.Fatalf("the page request is still running after a new query started")
+			}
+
+			if page.err != context.Canceled {
+				t.Errorf("page came back with %v, want it canceled", page.err)
+			}
+
+			if m.loadingMore {
+				t.Errorf("still loading more matches")
+			}
+
+			// another page started since isn't touched by the canceled one
+			// coming back late
+			m.loadingMore = true
+			next, _ := m.Update(page)
+
+			if !next.(Model).loadingMore {
+				t.Errorf("the canceled page was taken for the one loading now")
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// preferences kept between launches, unset ones fall back to the flag defaults
+type Prefs struct {
+	Theme       string `json:"theme,omitempty"`
+	Timezone    string `json:"timezone,omitempty"`
+	TimeFormat  string `json:"time_format,omitempty"`
+	Precision   *int   `json:"precision,omitempty"`
+	GraphHeight *int   `json:"graph_height,omitempty"`
+	Top         *int   `json:"top,omitempty"`
+	View        string `json:"view,omitempty"`
+	// seconds
+	RefreshInterval *int `json:"refresh_interval,omitempty"`
+}
+
+func prefsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "a-cli", "config"), nil
+}
+
+// a missing file is no preferences, a corrupt one is reported and ignored
+func loadPrefs() (Prefs, error) {
+	path, err := prefsPath()
+
+	if err != nil {
+		return Prefs{}, err
+	}
+
+	data, err := os.ReadFile(path)
+
+	if errors.Is(err, os.ErrNotExist) {
+		return Prefs{}, nil
+	} else if err != nil {
+		return Prefs{}, err
+	}
+
+	var prefs Prefs
+
+	if err := json.Unmarshal(data, &prefs); err != nil {
+		return Prefs{}, fmt.Errorf("could not read %v: %w", path, err)
+	}
+
+	if err := prefs.validate(); err != nil {
+		return Prefs{}, fmt.Errorf("could not use %v: %w", path, err)
+	}
+
+	return prefs, nil
+}
+
+func savePrefs(prefs Prefs) error {
+	path, err := prefsPath()
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(prefs, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+// the same bounds the flags are held to, so a bad file can't stop a launch
+func (p Prefs) validate() error {
+	if p.Theme != "" {
+		if _, err := getTheme(p.Theme); err != nil {
+			return err
+		}
+	}
+
+	if p.Precision != nil && *p.Precision != AUTO_PRECISION && (*p.Precision < 0 || *p.Precision > 4) {
+		return fmt.Errorf("precision must be between 0 and 4, got %v", *p.Precision)
+	}
+
+	if p.GraphHeight != nil && *p.GraphHeight != AUTO_GRAPH_HEIGHT && *p.GraphHeight < MIN_GRAPH_HEIGHT {
+		return fmt.Errorf("graph height must be at least %v, got %v", MIN_GRAPH_HEIGHT, *p.GraphHeight)
+	}
+
+	if p.Top != nil && *p.Top < 0 {
+		return fmt.Errorf("top can't be negative, got %v", *p.Top)
+	}
+
+	if p.RefreshInterval != nil && *p.RefreshInterval < MIN_REFRESH_INTERVAL {
+		return fmt.Errorf("refresh interval must be at least %v seconds, got %v", MIN_REFRESH_INTERVAL, *p.RefreshInterval)
+	}
+
+	if p.View != "" && !stringInSlice(p.View, VIEW_MODES) {
+		return fmt.Errorf("unknown view %q, expected one of: %v", p.View, VIEW_MODES)
+	}
+
+	return nil
+}
+
+// flags given on the command line win over the preferences, which win over
+// the flag defaults
+func (p Prefs) applyToFlags() {
+	set := map[string]bool{}
+
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	apply := func(name string, value string) {
+		if value != "" && !set[name] {
+			flag.Set(name, value)
+		}
+	}
+
+	apply("theme", p.Theme)
+	apply("time-format", p.TimeFormat)
+
+	// --utc and --local given on the command line win over a saved timezone
+	if !set["utc"] && !set["local"] {
+		apply("timezone", p.Timezone)
+	}
+
+	for name, value := range map[string]*int{"precision": p.Precision, "graph-height": p.GraphHeight, "top": p.Top, "refresh": p.RefreshInterval} {
+		if value != nil {
+			apply(name, strconv.Itoa(*value))
+		}
+	}
+}
+
+// holding + down or cycling through the themes writes the file once, after
+// the last change
+const PREFS_SAVE_DELAY = 500 * time.Millisecond
+
+type SavePrefsMsg struct {
+	gen int
+}
+
+// changes made while running are saved once they settle, see WritePrefs
+func (m *Model) SavePrefs(update func(*Prefs)) tea.Cmd {
+	update(&m.prefs)
+
+	m.prefsGen += 1
+	m.prefsUnsaved = true
+	gen := m.prefsGen
+
+	return tea.Tick(PREFS_SAVE_DELAY, func(time.Time) tea.Msg {
+		return SavePrefsMsg{gen: gen}
+	})
+}
+
+// a copy is written off the UI loop, unless another change came in since
+func (m *Model) WritePrefs(msg SavePrefsMsg) tea.Cmd {
+	if msg.gen != m.prefsGen || !m.prefsUnsaved {
+		return nil
+	}
+
+	m.prefsUnsaved = false
+	prefs := m.prefs
+
+	return func() tea.Msg {
+		if err := savePrefs(prefs); err != nil {
+			return Msg{
+				update: func(m *Model) {
+					m.setMsg(fmt.Sprintf("Could not save preferences: %v", err))
+				},
+			}
+		}
+
+		return nil
+	}
+}
+
+// on quit there's no waiting for a change to settle, and no one to tell it
+// couldn't be saved
+func (m *Model) FlushPrefs() {
+	if m.prefsUnsaved {
+		m.prefsUnsaved = false
+		savePrefs(m.prefs)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/exp/slices"
+)
+
+func TestPrefsFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	interval := 10
+	prefs := Prefs{Theme: "mono", Timezone: "UTC", RefreshInterval: &interval}
+
+	if err := savePrefs(prefs); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "a-cli", "config")); err != nil {
+		t.Fatalf("not saved to a-cli/config: %v", err)
+	}
+
+	loaded, err := loadPrefs()
+
+	if err != nil || !reflect.DeepEqual(loaded, prefs) {
+		t.Errorf("loaded %+v (error %v), want %+v", loaded, err, prefs)
+	}
+}
+
+// a file that can't be used leaves every preference at its default
+func TestPrefsFileUnusable(t *testing.T) {
+	tests := map[string]string{
+		"missing":               "",
+		"corrupt":               "{not json",
+		"bad theme":             `{"theme": "neon"}`,
+		"too short an interval": `{"refresh_interval": 1}`,
+	}
+
+	for name, content := range tests {
+		dir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", dir)
+
+		if content != "" {
+			os.MkdirAll(filepath.Join(dir, "a-cli"), 0o755)
+			os.WriteFile(filepath.Join(dir, "a-cli", "config"), []byte(content), 0o644)
+		}
+
+		prefs, err := loadPrefs()
+
+		if !reflect.DeepEqual(prefs, Prefs{}) {
+			t.Errorf("%v: loaded %+v, want no preferences", name, prefs)
+		}
+
+		if (err == nil) != (content == "") {
+			t.Errorf("%v: got error %v", name, err)
+		}
+	}
+}
+
+// waits out the delay of the last change and writes it, as Update would
+func settlePrefs(m *Model, cmd tea.Cmd) {
+	if write := m.WritePrefs(cmd().(SavePrefsMsg)); write != nil {
+		write()
+	}
+}
+
+func TestRuntimeChangesSaved(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	m := withResult(testModel(nil), "['logs']", groupedResult())
+
+	m.CycleTheme()
+	m.ToggleTimezone()
+	settlePrefs(&m, m.CycleRefreshInterval())
+
+	prefs, err := loadPrefs()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if prefs.Theme != "mono" || m.theme.name != "mono" {
+		t.Errorf("theme saved as %q, showing %q, want mono after default", prefs.Theme, m.theme.name)
+	}
+
+	if m.location != time.Local || prefs.Timezone != "Local" {
+		t.Errorf("timezone saved as %q, showing %v, want the local one after UTC", prefs.Timezone, m.location)
+	}
+
+	if m.refreshInterval != 10 || prefs.RefreshInterval == nil || *prefs.RefreshInterval != 10 {
+		t.Errorf("refreshing every %v, want 10 after 5", m.refreshInterval)
+	}
+
+	if color := (*m.graphs)[0].colors[0]; !slices.Contains(THEMES["mono"].colors, color) {
+		t.Errorf("graphed in %v, not a mono color", color)
+	}
+}
+
+// holding + down is a burst of changes, only the last of them is written
+func TestPrefsSavedOnceSettled(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	m := testModel(nil)
+	saves := []tea.Cmd{}
+
+	for i := 0; i < 5; i++ {
+		saves = append(saves, m.ResizeGraphs(1))
+	}
+
+	path, _ := prefsPath()
+
+	if _, err := os.Stat(path); err == nil {
+		t.Fatalf("written before the changes settled")
+	}
+
+	for _, save := range saves[:len(saves)-1] {
+		if write := m.WritePrefs(save().(SavePrefsMsg)); write != nil {
+			t.Fatalf("a change with another after it was written")
+		}
+	}
+
+	settlePrefs(&m, saves[len(saves)-1])
+	prefs, err := loadPrefs()
+
+	if err != nil || prefs.GraphHeight == nil || *prefs.GraphHeight != m.fixedGraphHeight {
+		t.Errorf("saved %+v (error %v), want the graph height of %v", prefs, err, m.fixedGraphHeight)
+	}
+}
+
+// a change still waiting to settle isn't lost on quit
+func TestPrefsFlushedOnQuit(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	m := withResult(testModel(nil), "['logs']", groupedResult())
+
+	for _, key := range []string{"p", "ctrl+c"} {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+
+		if key == "ctrl+c" {
+			msg = tea.KeyMsg{Type: tea.KeyCtrlC}
+		}
+
+		next, _ := m.Update(msg)
+		m = next.(Model)
+	}
+
+	prefs, err := loadPrefs()
+
+	if err != nil || prefs.Precision == nil || *prefs.Precision != m.precision {
+		t.Errorf("saved %+v (error %v), want the precision of %v", prefs, err, m.precision)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/axiomhq/axiom-go/axiom"
+	axiomQuery "github.com/axiomhq/axiom-go/axiom/query"
+)
+
+// all the model needs to run a query, so it can be run against something
+// other than Axiom
+type Querier interface {
+	Query(ctx context.Context, apl string) (*axiomQuery.Result, error)
+}
+
+// optional, queriers that can't list datasets just don't complete them
+type DatasetLister interface {
+	ListDatasets(ctx context.Context) ([]string, error)
+}
+
+// optional, shown in the status bar
+type Connection interface {
+	ConnectedTo() string
+}
+
+// optional, without it there's no loading more matches past the first result
+type Pager interface {
+	QueryPage(ctx context.Context, apl string, cursor string) (*axiomQuery.Result, error)
+}
+
+// what axiom.NewClient falls back to without AXIOM_URL
+const DEFAULT_API_URL = "https://api.axiom.co"
+
+func newQuerier(config Config) Querier {
+	// a replay needs no credentials
+	if config.replay != nil {
+		return replayQuerier{result: config.replay}
+	}
+
+	client, err := axiom.NewClient(
+	// axiom.SetPersonalTokenConfig("AXIOM_TOKEN", "AXIOM_ORG_ID"),
+	// axiom.SetURL("AXIOM_URL"),
+	)
+
+	if err != nil {
+		exitWithError(err)
+	}
+
+	// the client reads the same variables, but doesn't expose them
+	apiURL := os.Getenv("AXIOM_URL")
+
+	if apiURL == "" {
+		apiURL = DEFAULT_API_URL
+	}
+
+	return axiomQuerier{client: client, org: os.Getenv("AXIOM_ORG_ID"), url: apiURL}
+}
+
+type axiomQuerier struct {
+	client *axiom.Client
+	org    string
+	url    string
+}
+
+func (q axiomQuerier) ConnectedTo() string {
+	host := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(q.url, "https://"), "http://"), "/")
+
+	if q.org == "" {
+		return host
+	}
+
+	return fmt.Sprintf("%v @ %v", q.org, host)
+}
+
+func (q axiomQuerier) Query(ctx context.Context, apl string) (*axiomQuery.Result, error) {
+	return q.client.Query(ctx, apl)
+}
+
+// the rows past the cursor, leaving out the row it points at
+func (q axiomQuerier) QueryPage(ctx context.Context, apl string, cursor string) (*axiomQuery.Result, error) {
+	return q.client.Query(ctx, apl, axiomQuery.SetCursor(cursor, false))
+}
+
+func (q axiomQuerier) ListDatasets(ctx context.Context) ([]string, error) {
+	datasets, err := q.client.Datasets.List(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(datasets))
+
+	for _, dataset := range datasets {
+		names = append(names, dataset.Name)
+	}
+
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// answers every query with the same recorded result
+type replayQuerier struct {
+	result *axiomQuery.Result
+}
+
+func (q replayQuerier) ConnectedTo() string {
+	return "replaying a saved result"
+}
+
+func (q replayQuerier) Query(ctx context.Context, apl string) (*axiomQuery.Result, error) {
+	return q.result, nil
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	axiomQuery "github.com/axiomhq/axiom-go/axiom/query"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// answers each query with the result or error canned for its apl, and keeps
+// the apl of every query it was sent
+type fakeQuerier struct {
+	results map[string]*axiomQuery.Result
+	errs    map[string]error
+	sent    []string
+}
+
+func (q *fakeQuerier) Query(ctx context.Context, apl string) (*axiomQuery.Result, error) {
+	q.sent = append(q.sent, apl)
+
+	if err, ok := q.errs[apl]; ok {
+		return nil, err
+	}
+
+	if result, ok := q.results[apl]; ok {
+		return result, nil
+	}
+
+	return &axiomQuery.Result{}, nil
+}
+
+// runs the commands for the query until its result comes back, whatever
+// else they were batched with is left running
+func queryResult(t *testing.T, cmd tea.Cmd) ResultMsg {
+	t.Helper()
+
+	results := make(chan ResultMsg, 1)
+	var run func(cmd tea.Cmd)
+
+	run = func(cmd tea.Cmd) {
+		if cmd == nil {
+			return
+		}
+
+		switch msg := cmd().(type) {
+		case ResultMsg:
+			results <- msg
+		case tea.BatchMsg:
+			for _, cmd := range msg {
+				go run(cmd)
+			}
+		}
+	}
+
+	go run(cmd)
+
+	select {
+	case msg := <-results:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("the query never came back")
+		return ResultMsg{}
+	}
+}
+
+// the query typed and run with ctrl+s, up to its result on screen
+func runTyped(t *testing.T, m Model, apl string) Model {
+	t.Helper()
+
+	m.textarea.SetValue(apl)
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	m = next.(Model)
+
+	next, _ = m.Update(queryResult(t, cmd))
+
+	return next.(Model)
+}
+
+func TestQueryThroughQuerier(t *testing.T) {
+	apl := "['logs'] | limit 5"
+	client := &fakeQuerier{results: map[string]*axiomQuery.Result{
+		apl: {Matches: testMatches(5)},
+	}}
+
+	m := runTyped(t, testModel(client), apl)
+
+	if len(client.sent) != 1 || client.sent[0] != apl {
+		t.Fatalf("sent %q, want just %q", client.sent, apl)
+	}
+
+	if m.query.err != nil {
+		t.Fatalf("got error %v", m.query.err)
+	}
+
+	if m.matchesTable == nil || len(m.matchesTable.Rows()) != 5 {
+		t.Errorf("want a table of the 5 matches")
+	}
+}
+
+func TestQueryErrorThroughQuerier(t *testing.T) {
+	apl := "['missing']"
+	client := &fakeQuerier{errs: map[string]error{
+		apl: errors.New("dataset not found"),
+	}}
+
+	m := runTyped(t, testModel(client), apl)
+
+	if m.query.err == nil || m.query.err.Error() != "dataset not found" {
+		t.Fatalf("got error %v, want the querier's", m.query.err)
+	}
+
+	if m.ViewError() == "" {
+		t.Errorf("the error isn't shown")
+	}
+
+	if m.queryInFlight {
+		t.Errorf("still in flight after the error came back")
+	}
+}
+
+// a refresh slower than the countdown doesn't get another one stacked on it,
+// the countdown starts over once its result is in
+func TestNoRefreshWhileInFlight(t *testing.T) {
+	apl := "['logs'] | limit 5"
+	client := &fakeQuerier{}
+
+	m := runTyped(t, testModel(client), apl)
+	refreshCmd := m.RefreshQuery()
+	gen := m.queryGen
+
+	next, cmd := m.Update(ReRunMsg{gen: m.refreshGen})
+	m = next.(Model)
+
+	if m.queryGen != gen || cmd != nil {
+		t.Fatalf("another query was started while the refresh was in flight")
+	}
+
+	next, _ = m.Update(queryResult(t, refreshCmd))
+	m = next.(Model)
+
+	if len(client.sent) != 2 {
+		t.Errorf("sent %v queries, want the query and its refresh", len(client.sent))
+	}
+
+	if m.queryInFlight || m.state != REFRESHING || m.refreshTimeout != m.refreshInterval {
+		t.Errorf("the countdown didn't start over after the refresh came back")
+	}
+}
+
+// with nothing but matches they have the keys as soon as the result is in
+func TestMatchesOnlyNavigable(t *testing.T) {
+	apl := "['logs'] | limit 5"
+	client := &fakeQuerier{results: map[string]*axiomQuery.Result{
+		apl: {Matches: testMatches(5)},
+	}}
+
+	m := runTyped(t, testModel(client), apl)
+
+	for _, key := range []tea.KeyType{tea.KeyDown, tea.KeyDown, tea.KeyUp} {
+		next, _ := m.Update(tea.KeyMsg{Type: key})
+		m = next.(Model)
+	}
+
+	if got := m.matchesTable.Cursor(); got != 1 {
+		t.Errorf("matches cursor is %v after down, down, up, want 1", got)
+	}
+
+	if m.matchesTableHighlightedIdx != 1 {
+		t.Errorf("highlighted match %v, want the one under the cursor", m.matchesTableHighlightedIdx)
+	}
+}
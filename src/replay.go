@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	axiomQuery "github.com/axiomhq/axiom-go/axiom/query"
+)
+
+// a result as shown by the raw view, so a copy of it can be replayed
+func loadReplay(path string) (*axiomQuery.Result, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var replay replayResult
+
+	if err := json.Unmarshal(data, &replay); err != nil {
+		return nil, fmt.Errorf("could not read the result in %v: %w", path, err)
+	}
+
+	result := replay.Result
+	result.Status = axiomQuery.Status(replay.Status)
+
+	// marshaled in microseconds, the way the server sends it
+	result.Status.ElapsedTime *= time.Microsecond
+
+	return &result, nil
+}
+
+// the status is decoded without its own UnmarshalJSON, which recurses into
+// itself with newer versions of encoding/json
+type replayStatus axiomQuery.Status
+
+type replayResult struct {
+	axiomQuery.Result
+	Status replayStatus `json:"status"`
+}
+
+// one timestamped file per result, in the format --replay reads back
+func recordResult(dir string, result *axiomQuery.Result) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("result-%v.json", time.Now().Format("20060102-150405.000000000"))
+
+	return os.WriteFile(filepath.Join(dir, name), data, 0o644)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"time"
+
+	axiomQuery "github.com/axiomhq/axiom-go/axiom/query"
+)
+
+// where the cursors were before a result rebuilt the tables
+type tableSelection struct {
+	totalsKey        string
+	totalsCursor     int
+	totalsFocused    bool
+	intervalsCursor  int
+	matchKey         uint64
+	matchTime        time.Time
+	matchCursor      int
+	matchHighlighted bool
+}
+
+func (m Model) saveSelection() *tableSelection {
+	selection := &tableSelection{totalsCursor: -1, intervalsCursor: -1, matchCursor: -1}
+
+	if m.totalsTable != nil && m.queryMeta != nil && len(m.totalsTable.SelectedRow()) > 0 {
+		selection.totalsKey = m.rowGroupKey(m.totalsTable.SelectedRow())
+		selection.totalsCursor = m.totalsTable.Cursor()
+		selection.totalsFocused = m.totalsTable.Focused()
+	}
+
+	if m.intervalsTable != nil {
+		selection.intervalsCursor = m.intervalsTable.Cursor()
+	}
+
+	if m.matchesTable != nil && m.matchesTable.Cursor() >= 0 && m.matchesTable.Cursor() < len(m.matches) {
+		match := m.matches[m.matchesTable.Cursor()]
+
+		selection.matchKey = matchKey(match)
+		selection.matchTime = match.Time
+		selection.matchCursor = m.matchesTable.Cursor()
+		selection.matchHighlighted = m.matchesTableHighlightedIdx != -1
+	}
+
+	return selection
+}
+
+// totals are found again by their group and matches by their content or
+// time, rows that are gone leave the cursor where it was
+func (m *Model) restoreSelection(selection *tableSelection) {
+	if m.totalsTable != nil && selection.totalsCursor != -1 {
+		cursor := selection.totalsCursor
+
+		for i, row := range m.totalsTable.Rows() {
+			if m.rowGroupKey(row) == selection.totalsKey {
+				cursor = i
+				break
+			}
+		}
+
+		m.totalsTable.SetCursor(minInt(cursor, len(m.totalsTable.Rows())-1))
+
+		if selection.totalsFocused {
+			m.totalsTable.Focus()
+		}
+	}
+
+	if m.intervalsTable != nil && selection.intervalsCursor != -1 {
+		m.intervalsTable.SetCursor(minInt(selection.intervalsCursor, len(m.intervalsTable.Rows())-1))
+	}
+
+	if m.matchesTable != nil && selection.matchCursor != -1 {
+		m.matchesTable.SetCursor(minInt(findMatch(m.matches, selection), len(m.matches)-1))
+
+		if selection.matchHighlighted {
+			m.UpdateMatchesHighlight()
+		}
+	}
+}
+
+func findMatch(matches []axiomQuery.Entry, selection *tableSelection) int {
+	sameTime := -1
+
+	for i, match := range matches {
+		if matchKey(match) == selection.matchKey {
+			return i
+		}
+
+		if sameTime == -1 && match.Time.Equal(selection.matchTime) {
+			sameTime = i
+		}
+	}
+
+	if sameTime != -1 {
+		return sameTime
+	}
+
+	return selection.matchCursor
+}
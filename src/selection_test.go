@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+// an auto refresh returning the very same rows leaves both cursors alone
+func TestSelectionSurvivesRefresh(t *testing.T) {
+	apl := "['logs'] | summarize count(), avg(duration) by bin(_time, 1m), method"
+	result := groupedResult()
+	result.Matches = testMatches(5)
+
+	m := withResult(testModel(nil), apl, result)
+
+	if m.totalsTable == nil || m.matchesTable == nil {
+		t.Fatal("want both the totals and the matches table")
+	}
+
+	m.totalsTable.SetCursor(1)
+	m.matchesTable.SetCursor(3)
+
+	m = withResult(m, apl, result)
+
+	if got := m.totalsTable.Cursor(); got != 1 {
+		t.Errorf("totals cursor is %v after the refresh, want 1", got)
+	}
+
+	if got := m.matchesTable.Cursor(); got != 3 {
+		t.Errorf("matches cursor is %v after the refresh, want 3", got)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// past this many series the stats only list how many more there are
+const MAX_STATS_SERIES = 5
+
+// NaNs are skipped, a series without any number is NaN throughout
+func seriesStats(values []float64) (float64, float64, float64) {
+	lowest, highest, sum := math.Inf(1), math.Inf(-1), 0.0
+	count := 0
+
+	for _, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+
+		lowest = math.Min(lowest, v)
+		highest = math.Max(highest, v)
+		sum += v
+		count += 1
+	}
+
+	if count == 0 {
+		return math.NaN(), math.NaN(), math.NaN()
+	}
+
+	return lowest, highest, sum / float64(count)
+}
+
+// the series in color, so with a group highlighted or selected only those
+func (m Model) statsSeries(graph GraphData) []int {
+	series := []int{}
+
+	for i, group := range graph.groups {
+		if !m.groupDimmed(group) {
+			series = append(series, i)
+		}
+	}
+
+	return series
+}
+
+// one line per series under the plot, in the series color
+func (m Model) ViewSeriesStats(graph GraphData, width int) string {
+	series := m.statsSeries(graph)
+	lines := appendIfNotEmpty([]string{}, m.ViewThreshold(graph))
+
+	// rounded like the y axis labels
+	precision := m.precision
+
+	if precision == AUTO_PRECISION {
+		precision = autoPrecision(graph.data)
+	}
+
+	format := func(v float64) string {
+		scale := math.Pow10(precision)
+
+		return m.formatOpValue(graph.op, math.Round(v*scale)/scale)
+	}
+
+	for _, i := range series[:minInt(len(series), MAX_STATS_SERIES)] {
+		// empty series are dropped from the graph, so there is always a number
+		lowest, highest, mean := seriesStats(graph.data[i])
+
+		style := lipgloss.NewStyle().Foreground(ansiColor(graph.colors[i]))
+		lines = append(lines, style.Render(fmt.Sprintf("■ %v  min %v  max %v  avg %v", graph.labels[i], format(lowest), format(highest), format(mean))))
+	}
+
+	hintStyle := lipgloss.NewStyle().Foreground(m.theme.hintColor)
+
+	if hidden := len(series) - MAX_STATS_SERIES; hidden > 0 {
+		lines = append(lines, hintStyle.Render(fmt.Sprintf("(+%v more)", hidden)))
+	}
+
+	noData := []string{}
+
+	for _, group := range graph.noData {
+		if !m.groupDimmed(group) {
+			noData = append(noData, group)
+		}
+	}
+
+	if len(noData) > 0 {
+		lines = append(lines, hintStyle.Render(fmt.Sprintf("no data: %v", strings.Join(noData, ", "))))
+	}
+
+	return lipgloss.NewStyle().MaxWidth(width).Render(strings.Join(lines, "\n"))
+}
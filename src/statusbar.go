@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var STATE_NAMES = map[int]string{
+	TYPING:     "typing",
+	QUERYING:   "querying",
+	REFRESHING: "refreshing",
+}
+
+func connectionOf(client Querier) string {
+	if connection, ok := client.(Connection); ok {
+		return connection.ConnectedTo()
+	}
+
+	return ""
+}
+
+// one line at the very bottom: where the results come from, when the last
+// one came in and what the keys do right now
+func (m Model) ViewStatusBar() string {
+	items := []string{STATE_NAMES[m.state]}
+
+	if m.connection != "" {
+		items = append(items, m.connection)
+	}
+
+	if !m.lastResultAt.IsZero() {
+		items = append(items, fmt.Sprintf("last result %v", m.lastResultAt.In(m.location).Format("15:04:05")))
+	}
+
+	if alerts := m.alertCount(); alerts > 0 {
+		items = append(items, fmt.Sprintf("⚠ %v over threshold", alerts))
+	}
+
+	style := lipgloss.NewStyle().
+		Foreground(m.theme.highlightForeground).
+		Background(m.theme.highlightBackground).
+		Padding(0, 1)
+
+	if m.width > 0 {
+		style = style.Width(m.width).MaxWidth(m.width)
+	}
+
+	return style.Render(strings.Join(items, " · "))
+}
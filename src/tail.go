@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"time"
+
+	axiomQuery "github.com/axiomhq/axiom-go/axiom/query"
+)
+
+// the oldest matches are dropped past this so a long tail stays responsive
+const MAX_TAIL_MATCHES = 10000
+
+// only what happened after the newest match seen so far
+func tailQuery(apl string, since time.Time) string {
+	return insertWhere(apl, fmt.Sprintf("where _time > datetime(%v)", since.UTC().Format(time.RFC3339Nano)))
+}
+
+func newestMatchTime(matches []axiomQuery.Entry) time.Time {
+	var newest time.Time
+
+	for _, match := range matches {
+		if match.Time.After(newest) {
+			newest = match.Time
+		}
+	}
+
+	return newest
+}
+
+// matches have no stable id across queries, so identify them by their time
+// and contents
+func matchKey(match axiomQuery.Entry) uint64 {
+	hash := fnv.New64a()
+
+	// maps print with sorted keys, so equal data hashes the same
+	fmt.Fprintf(hash, "%v|%v", match.Time.UnixNano(), match.Data)
+
+	return hash.Sum64()
+}
+
+// new matches go on top, newest first, skipping any already seen
+func mergeTailMatches(seen []axiomQuery.Entry, incoming []axiomQuery.Entry) ([]axiomQuery.Entry, int) {
+	keys := map[uint64]bool{}
+
+	for _, match := range seen {
+		keys[matchKey(match)] = true
+	}
+
+	fresh := []axiomQuery.Entry{}
+
+	for _, match := range incoming {
+		key := matchKey(match)
+
+		if keys[key] {
+			continue
+		}
+
+		keys[key] = true
+		fresh = append(fresh, match)
+	}
+
+	sort.SliceStable(fresh, func(i, j int) bool {
+		return fresh[i].Time.After(fresh[j].Time)
+	})
+
+	merged := append(fresh, seen...)
+
+	if len(merged) > MAX_TAIL_MATCHES {
+		merged = merged[:MAX_TAIL_MATCHES]
+	}
+
+	return merged, len(fresh)
+}
+
+// folds an incremental result into the one being tailed
+func (m *Model) MergeTail(msg ResultMsg) (ResultMsg, int) {
+	if msg.since.IsZero() || msg.err != nil || msg.result == nil || m.query.result == nil {
+		return msg, 0
+	}
+
+	result := *msg.result
+
+	var fresh int
+	result.Matches, fresh = mergeTailMatches(m.query.result.Matches, msg.result.Matches)
+	msg.result = &result
+
+	return msg, fresh
+}
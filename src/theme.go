@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	asciigraph "github.com/guptarohit/asciigraph"
+	"golang.org/x/exp/slices"
+)
+
+type Theme struct {
+	// set by getTheme, from its key in THEMES
+	name                string
+	colors              []asciigraph.AnsiColor
+	pulseColors         []string
+	dimColor            asciigraph.AnsiColor
+	highlightForeground lipgloss.Color
+	highlightBackground lipgloss.Color
+	// graph borders and keys in the help
+	accentColor lipgloss.Color
+	// hints, axis labels and anything else that's there but not the point
+	hintColor    lipgloss.Color
+	warningColor lipgloss.Color
+	// the groups past the ones graphed, summed up
+	otherColor     asciigraph.AnsiColor
+	thresholdColor asciigraph.AnsiColor
+	syntax         SyntaxColors
+}
+
+// for the query as it's typed and the raw JSON of a match
+type SyntaxColors struct {
+	pipe     lipgloss.Color
+	keyword  lipgloss.Color
+	function lipgloss.Color
+	str      lipgloss.Color
+	number   lipgloss.Color
+	boolean  lipgloss.Color
+	key      lipgloss.Color
+	null     lipgloss.Color
+}
+
+var THEMES = map[string]Theme{
+	"default": {
+		colors: []asciigraph.AnsiColor{
+			asciigraph.Blue,
+			asciigraph.Magenta,
+			asciigraph.Cyan,
+			asciigraph.Green,
+			asciigraph.Yellow,
+			asciigraph.Red,
+			asciigraph.AliceBlue,
+			asciigraph.Cornsilk,
+			asciigraph.Crimson,
+			asciigraph.DarkViolet,
+			asciigraph.DeepPink,
+			asciigraph.Gold,
+			asciigraph.Indigo,
+			asciigraph.Lavender,
+			asciigraph.LightCoral,
+			asciigraph.LightSalmon,
+		},
+		pulseColors: []string{
+			"#432155",
+			"#4e2667",
+			"#5f2d84",
+			"#7938b2",
+			"#8e4ec6",
+			"#9d5bd2",
+			"#8e4ec6",
+			"#7938b2",
+			"#5f2d84",
+			"#4e2667",
+		},
+		dimColor:            asciigraph.SlateGray,
+		highlightForeground: lipgloss.Color("229"),
+		highlightBackground: lipgloss.Color("57"),
+		accentColor:         lipgloss.Color("69"),
+		hintColor:           lipgloss.Color("241"),
+		warningColor:        lipgloss.Color("214"),
+		otherColor:          asciigraph.DarkGray,
+		thresholdColor:      asciigraph.Red,
+		syntax: SyntaxColors{
+			pipe:     lipgloss.Color("205"),
+			keyword:  lipgloss.Color("69"),
+			function: lipgloss.Color("43"),
+			str:      lipgloss.Color("186"),
+			number:   lipgloss.Color("141"),
+			boolean:  lipgloss.Color("205"),
+			key:      lipgloss.Color("69"),
+			null:     lipgloss.Color("241"),
+		},
+	},
+	"solarized": {
+		colors: []asciigraph.AnsiColor{
+			33,  // blue
+			125, // magenta
+			37,  // cyan
+			64,  // green
+			136, // yellow
+			160, // red
+			166, // orange
+			61,  // violet
+		},
+		pulseColors: []string{
+			"#073642",
+			"#0b4a5a",
+			"#115e73",
+			"#1a738c",
+			"#268bd2",
+			"#2aa198",
+			"#268bd2",
+			"#1a738c",
+			"#115e73",
+			"#0b4a5a",
+		},
+		dimColor:            240,
+		highlightForeground: lipgloss.Color("230"),
+		highlightBackground: lipgloss.Color("33"),
+		accentColor:         lipgloss.Color("33"),
+		hintColor:           lipgloss.Color("240"),
+		warningColor:        lipgloss.Color("166"),
+		otherColor:          244,
+		thresholdColor:      160,
+		syntax: SyntaxColors{
+			pipe:     lipgloss.Color("125"),
+			keyword:  lipgloss.Color("33"),
+			function: lipgloss.Color("37"),
+			str:      lipgloss.Color("64"),
+			number:   lipgloss.Color("61"),
+			boolean:  lipgloss.Color("125"),
+			key:      lipgloss.Color("33"),
+			null:     lipgloss.Color("240"),
+		},
+	},
+	// grayscale only, for screenshots and accessibility
+	"mono": {
+		colors: []asciigraph.AnsiColor{
+			255,
+			250,
+			246,
+			252,
+			248,
+			244,
+		},
+		pulseColors: []string{
+			"#3a3a3a",
+			"#4e4e4e",
+			"#626262",
+			"#767676",
+			"#8a8a8a",
+			"#9e9e9e",
+			"#8a8a8a",
+			"#767676",
+			"#626262",
+			"#4e4e4e",
+		},
+		dimColor:            238,
+		highlightForeground: lipgloss.Color("232"),
+		highlightBackground: lipgloss.Color("252"),
+		accentColor:         lipgloss.Color("252"),
+		hintColor:           lipgloss.Color("243"),
+		warningColor:        lipgloss.Color("255"),
+		otherColor:          242,
+		// plain white, a step up from the brightest group
+		thresholdColor: 231,
+		syntax: SyntaxColors{
+			pipe:     lipgloss.Color("255"),
+			keyword:  lipgloss.Color("252"),
+			function: lipgloss.Color("250"),
+			str:      lipgloss.Color("246"),
+			number:   lipgloss.Color("248"),
+			boolean:  lipgloss.Color("255"),
+			key:      lipgloss.Color("252"),
+			null:     lipgloss.Color("243"),
+		},
+	},
+}
+
+func themeNames() []string {
+	var names []string = []string{}
+
+	for name := range THEMES {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func getTheme(name string) (Theme, error) {
+	theme, ok := THEMES[name]
+
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme %q, expected one of: %v", name, strings.Join(themeNames(), ", "))
+	}
+
+	theme.name = name
+
+	return theme, nil
+}
+
+// the next theme by name, with the groups recolored right away
+func (m *Model) CycleTheme() tea.Cmd {
+	names := themeNames()
+	next := names[(slices.Index(names, m.theme.name)+1)%len(names)]
+
+	m.theme, _ = getTheme(next)
+	m.refreshProgress = initRefreshProgress(m.theme, m.noColor)
+	m.setMsg(fmt.Sprintf("Theme: %v", next))
+
+	cmd := m.SavePrefs(func(prefs *Prefs) {
+		prefs.Theme = next
+	})
+
+	if m.queryMeta != nil {
+		m.queryMeta.groupColors = assignGroupColors(m.queryMeta.groups, m.theme.colors)
+		m.UpdateGraphs(m.query.result)
+	}
+
+	return cmd
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"image/color"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	asciigraph "github.com/guptarohit/asciigraph"
+	"github.com/muesli/termenv"
+)
+
+func isGray(c color.RGBA) bool {
+	return c.R == c.G && c.G == c.B
+}
+
+func isGrayColor(c lipgloss.Color) bool {
+	str := string(c)
+
+	if strings.HasPrefix(str, "#") {
+		rgb, err := strconv.ParseUint(str[1:], 16, 32)
+
+		return err == nil && isGray(color.RGBA{uint8(rgb >> 16), uint8(rgb >> 8), uint8(rgb), 255})
+	}
+
+	i, err := strconv.Atoi(str)
+
+	return err == nil && isGray(ansiRGB(asciigraph.AnsiColor(i)))
+}
+
+var sgrPattern = regexp.MustCompile("\x1b\\[([0-9;]*)m")
+
+// the foreground and background colors set anywhere in the rendered output
+// that aren't a shade of gray
+func colorfulCodes(rendered string) []string {
+	colorful := []string{}
+
+	for _, sgr := range sgrPattern.FindAllStringSubmatch(rendered, -1) {
+		params := strings.Split(sgr[1], ";")
+
+		for i := 0; i < len(params); i++ {
+			p, _ := strconv.Atoi(params[i])
+
+			switch {
+			case (p == 38 || p == 48) && i+2 < len(params) && params[i+1] == "5":
+				if !isGrayColor(lipgloss.Color(params[i+2])) {
+					colorful = append(colorful, sgr[1])
+				}
+				i += 2
+			case (p == 38 || p == 48) && i+4 < len(params) && params[i+1] == "2":
+				if params[i+2] != params[i+3] || params[i+3] != params[i+4] {
+					colorful = append(colorful, sgr[1])
+				}
+				i += 4
+			case p >= 30 && p <= 37 || p >= 40 && p <= 47:
+				if !isGray(ansiRGB(asciigraph.AnsiColor(p % 10))) {
+					colorful = append(colorful, sgr[1])
+				}
+			case p >= 90 && p <= 97 || p >= 100 && p <= 107:
+				if !isGray(ansiRGB(asciigraph.AnsiColor(p%10 + 8))) {
+					colorful = append(colorful, sgr[1])
+				}
+			}
+		}
+	}
+
+	return colorful
+}
+
+func TestMonoThemeColorsGray(t *testing.T) {
+	theme := THEMES["mono"]
+
+	colors := []lipgloss.Color{
+		theme.highlightForeground,
+		theme.highlightBackground,
+		theme.accentColor,
+		theme.hintColor,
+		theme.warningColor,
+		theme.syntax.pipe,
+		theme.syntax.keyword,
+		theme.syntax.function,
+		theme.syntax.str,
+		theme.syntax.number,
+		theme.syntax.boolean,
+		theme.syntax.key,
+		theme.syntax.null,
+	}
+
+	for _, c := range append(theme.colors, theme.dimColor, theme.otherColor, theme.thresholdColor) {
+		colors = append(colors, ansiColor(c))
+	}
+
+	for _, c := range theme.pulseColors {
+		colors = append(colors, lipgloss.Color(c))
+	}
+
+	for _, c := range colors {
+		if !isGrayColor(c) {
+			t.Errorf("%v isn't a shade of gray", c)
+		}
+	}
+}
+
+// nothing the views render in the mono theme is in color, hard-coded colors
+// included
+func TestMonoThemeRendersGray(t *testing.T) {
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	defer lipgloss.SetColorProfile(termenv.Ascii)
+
+	config := testConfig()
+	config.theme, _ = getTheme("mono")
+	config.noColor = false
+	config.thresholds = map[string]float64{"count_": 1}
+
+	m := newModel(config, nil)
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+	m = withResult(next.(Model), "['logs'] | summarize count() by bin_auto(_time)", groupedResult())
+	m.setMsg("a warning")
+
+	rendered := []string{
+		m.View(),
+		m.ViewHelp(),
+		m.ViewRaw(),
+		highlightApl("['logs'] | where msg contains 'x' | summarize count()", m.theme.syntax),
+		colorizeJson(`{"a": "b", "c": 1, "d": true, "e": null}`, m.theme.syntax),
+	}
+
+	for _, graph := range *m.graphs {
+		rendered = append(rendered, m.ViewThreshold(graph))
+	}
+
+	if !strings.Contains(rendered[3], "\x1b[") {
+		t.Fatalf("nothing rendered in color, the test can't tell")
+	}
+
+	if colorful := colorfulCodes(strings.Join(rendered, "\n")); len(colorful) > 0 {
+		t.Errorf("rendered in color: %q", colorful)
+	}
+}
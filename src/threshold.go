@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	asciigraph "github.com/guptarohit/asciigraph"
+)
+
+// how long the border of a graph that just went over stays red
+const FLASH_DURATION = 2 * time.Second
+
+type FlashMsg struct {
+	gen int
+}
+
+// long enough for the renderer to put out a frame with the bell in it, it
+// draws 60 a second
+const BELL_DURATION = 100 * time.Millisecond
+
+type BellMsg struct{}
+
+// only the newest interval with a number counts, a spike earlier in the
+// window that's gone by now isn't an alert
+func markOverThreshold(graph GraphData) GraphData {
+	graph.over = nil
+
+	if !graph.hasThreshold {
+		return graph
+	}
+
+	for i, series := range graph.data {
+		for j := len(series) - 1; j >= 0; j-- {
+			if math.IsNaN(series[j]) {
+				continue
+			}
+
+			if series[j] > graph.threshold {
+				graph.over = append(graph.over, graph.groups[i])
+			}
+
+			break
+		}
+	}
+
+	return graph
+}
+
+// the threshold is plotted as one more, flat series so the y axis always
+// has room for it
+func withThresholdLine(graph GraphData) ([][]float64, []asciigraph.AnsiColor) {
+	if !graph.hasThreshold || len(graph.data) == 0 {
+		return graph.data, graph.colors
+	}
+
+	line := make([]float64, len(graph.data[0]))
+
+	for i := range line {
+		line[i] = graph.threshold
+	}
+
+	data := append(append([][]float64{}, graph.data...), line)
+	colors := append(append([]asciigraph.AnsiColor{}, graph.colors...), graph.thresholdColor)
+
+	return data, colors
+}
+
+// with --bell an op going over rings and flashes its graph once, staying
+// over on the next refreshes doesn't
+func (m *Model) CheckThresholds() tea.Cmd {
+	over := map[string]bool{}
+	crossed := []string{}
+
+	if m.graphs != nil {
+		for _, graph := range *m.graphs {
+			if len(graph.over) == 0 {
+				continue
+			}
+
+			over[graph.op] = true
+
+			if !m.wasOver[graph.op] {
+				crossed = append(crossed, graph.op)
+			}
+		}
+	}
+
+	m.wasOver = over
+
+	if !m.bell || len(crossed) == 0 {
+		return nil
+	}
+
+	m.flashing = map[string]bool{}
+
+	for _, op := range crossed {
+		m.flashing[op] = true
+	}
+
+	m.flashGen += 1
+	gen := m.flashGen
+	m.bellRinging = true
+
+	return tea.Batch(
+		tea.Tick(BELL_DURATION, func(time.Time) tea.Msg {
+			return BellMsg{}
+		}),
+		tea.Tick(FLASH_DURATION, func(time.Time) tea.Msg {
+			return FlashMsg{gen: gen}
+		}),
+	)
+}
+
+// every group over the threshold of every op, hidden graphs included
+func (m Model) alertCount() int {
+	if m.graphs == nil {
+		return 0
+	}
+
+	count := 0
+
+	for _, graph := range *m.graphs {
+		count += len(graph.over)
+	}
+
+	return count
+}
+
+func (m Model) ViewThreshold(graph GraphData) string {
+	if !graph.hasThreshold {
+		return ""
+	}
+
+	label := fmt.Sprintf("─ threshold %v", m.formatOpValue(graph.op, graph.threshold))
+
+	if len(graph.over) > 0 {
+		label += fmt.Sprintf(" · over: %v", strings.Join(graph.over, ", "))
+	}
+
+	return lipgloss.NewStyle().Foreground(ansiColor(m.theme.thresholdColor)).Render(label)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// the BEL goes out at the start of a frame while the bell rings, and only
+// when an op goes over
+func TestBellRungFromView(t *testing.T) {
+	config := testConfig()
+	config.bell = true
+	config.thresholds = map[string]float64{"count_": 1}
+
+	m := newModel(config, nil)
+	m = withResult(m, "['logs']", groupedResult())
+
+	if !strings.HasPrefix(m.View(), "\a") {
+		t.Fatalf("no bell when count_ went over")
+	}
+
+	if strings.Count(m.View(), "\a") != 1 {
+		t.Errorf("rung more than once in a frame")
+	}
+
+	next, _ := m.Update(BellMsg{})
+	m = next.(Model)
+
+	if strings.Contains(m.View(), "\a") {
+		t.Errorf("still ringing after the bell was done")
+	}
+
+	// still over on the next result
+	m = withResult(m, "['logs']", groupedResult())
+
+	if strings.Contains(m.View(), "\a") {
+		t.Errorf("rung again for an op that was already over")
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const DEFAULT_TIME_FORMAT = "default"
+
+// between UTC and the local timezone, a timezone given with --timezone goes
+// to UTC first. The tables are rebuilt with their times in the new one.
+func (m *Model) ToggleTimezone() tea.Cmd {
+	if m.location == time.UTC {
+		m.location = time.Local
+		m.setMsg("Showing times in the local timezone")
+	} else {
+		m.location = time.UTC
+		m.setMsg("Showing times in UTC")
+	}
+
+	cmd := m.SavePrefs(func(prefs *Prefs) {
+		prefs.Timezone = m.location.String()
+	})
+
+	if m.query.result != nil {
+		selection := m.saveSelection()
+
+		m.UpdateIntervalsTable(m.query.result)
+		m.UpdateMatchesTable(m.query.result)
+		m.restoreSelection(selection)
+	}
+
+	return cmd
+}
+
+var TIME_FORMAT_PRESETS = map[string]string{
+	"rfc3339": time.RFC3339,
+	"kitchen": time.Kitchen,
+}
+
+// presets by name, anything else is used as a Go layout
+func formatTime(t time.Time, format string, location *time.Location) string {
+	t = t.In(location)
+
+	switch format {
+	case DEFAULT_TIME_FORMAT, "":
+		return t.String()
+	case "unix":
+		return fmt.Sprintf("%v", t.Unix())
+	case "relative":
+		return relativeTime(t, time.Now())
+	}
+
+	if layout, ok := TIME_FORMAT_PRESETS[format]; ok {
+		return t.Format(layout)
+	}
+
+	return t.Format(format)
+}
+
+func relativeTime(t time.Time, now time.Time) string {
+	d := now.Sub(t)
+
+	suffix := "ago"
+
+	if d < 0 {
+		d = -d
+		suffix = "from now"
+	}
+
+	switch {
+	case d < time.Second:
+		return "just now"
+	case d < time.Minute:
+		return fmt.Sprintf("%vs %v", int(d.Seconds()), suffix)
+	case d < time.Hour:
+		return fmt.Sprintf("%vm %v", int(d.Minutes()), suffix)
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%vh %v", int(d.Hours()), suffix)
+	default:
+		return fmt.Sprintf("%vd %v", int(d.Hours()/24), suffix)
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	axiomQuery "github.com/axiomhq/axiom-go/axiom/query"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var update = flag.Bool("update", false, "write the View output to the golden files in testdata")
+
+var TEST_START = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// bookmarks and preferences are read from a config dir of the tests' own
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "a-cli-test")
+
+	if err != nil {
+		panic(err)
+	}
+
+	os.Setenv("XDG_CONFIG_HOME", dir)
+	code := m.Run()
+	os.RemoveAll(dir)
+
+	os.Exit(code)
+}
+
+func testConfig() Config {
+	theme, _ := getTheme("default")
+
+	return Config{
+		theme:           theme,
+		precision:       AUTO_PRECISION,
+		refreshInterval: DEFAULT_REFRESH_INTERVAL,
+		location:        time.UTC,
+		timeFormat:      DEFAULT_TIME_FORMAT,
+		noColor:         true,
+		noSplash:        true,
+		spinnerType:     DEFAULT_SPINNER,
+		graphHeight:     AUTO_GRAPH_HEIGHT,
+		viewMode:        "all",
+		matchLimit:      1000,
+		vars:            map[string]string{},
+	}
+}
+
+// sized like a small terminal, with no result yet
+func testModel(client Querier) Model {
+	m := newModel(testConfig(), client)
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+
+	return next.(Model)
+}
+
+// the result of the last query run, as if it had just come in
+func withResult(m Model, apl string, result *axiomQuery.Result) Model {
+	next, _ := m.Update(ResultMsg{apl: apl, result: result, gen: m.queryGen})
+	m = next.(Model)
+
+	// shown in the status bar, the one thing that changes between runs
+	m.lastResultAt = time.Time{}
+
+	return m
+}
+
+func testMatches(count int) []axiomQuery.Entry {
+	matches := []axiomQuery.Entry{}
+
+	for i := 0; i < count; i++ {
+		matches = append(matches, axiomQuery.Entry{
+			Time:  TEST_START.Add(-time.Duration(i) * time.Minute),
+			RowID: string(rune('a' + i)),
+			Data: map[string]any{
+				"method": []string{"GET", "POST"}[i%2],
+				"status": 200 + i%3,
+			},
+		})
+	}
+
+	return matches
+}
+
+func testGroup(group map[string]any, values ...any) axiomQuery.EntryGroup {
+	aggregations := []axiomQuery.EntryGroupAgg{}
+
+	for i, value := range values {
+		aggregations = append(aggregations, axiomQuery.EntryGroupAgg{
+			Alias: []string{"count_", "avg_duration"}[i],
+			Value: value,
+		})
+	}
+
+	return axiomQuery.EntryGroup{Group: group, Aggregations: aggregations}
+}
+
+// one interval per row of groups, in order from TEST_START
+func testSeries(rows ...[]axiomQuery.EntryGroup) []axiomQuery.Interval {
+	series := []axiomQuery.Interval{}
+
+	for i, groups := range rows {
+		start := TEST_START.Add(time.Duration(i) * time.Minute)
+
+		series = append(series, axiomQuery.Interval{
+			StartTime: start,
+			EndTime:   start.Add(time.Minute),
+			Groups:    groups,
+		})
+	}
+
+	return series
+}
+
+func groupedResult() *axiomQuery.Result {
+	get := map[string]any{"method": "GET"}
+	post := map[string]any{"method": "POST"}
+
+	return &axiomQuery.Result{
+		Buckets: axiomQuery.Timeseries{
+			Series: testSeries(
+				[]axiomQuery.EntryGroup{testGroup(get, 3, 12.5), testGroup(post, 1, 40.0)},
+				[]axiomQuery.EntryGroup{testGroup(get, 5, 10.0), testGroup(post, 2, 35.0)},
+				[]axiomQuery.EntryGroup{testGroup(get, 4, 11.0), testGroup(post, 4, 30.0)},
+				[]axiomQuery.EntryGroup{testGroup(get, 6, 9.5), testGroup(post, 3, 32.5)},
+			),
+			Totals: []axiomQuery.EntryGroup{testGroup(get, 18, 10.75), testGroup(post, 10, 34.4)},
+		},
+	}
+}
+
+func TestView(t *testing.T) {
+	tests := []struct {
+		name   string
+		apl    string
+		result *axiomQuery.Result
+	}{
+		{"empty", "['logs'] | where status == 999", &axiomQuery.Result{}},
+		{"matches", "['logs'] | limit 5", &axiomQuery.Result{Matches: testMatches(5)}},
+		{"grouped", "['logs'] | summarize count(), avg(duration) by bin(_time, 1m), method", groupedResult()},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := testModel(nil)
+			m.textarea.SetValue(test.apl)
+			m = withResult(m, test.apl, test.result)
+
+			got := m.View()
+			path := filepath.Join("testdata", test.name+".golden")
+
+			if *update {
+				if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(path)
+
+			if err != nil {
+				t.Fatalf("%v, run go test -update to write it", err)
+			}
+
+			if got != string(want) {
+				t.Errorf("View() doesn't match %v, run go test -update if the change is intended\n\ngot:\n%v\n\nwant:\n%v", path, got, string(want))
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// the file is stat'ed on a tick of its own, the refresh countdown only runs
+// while there's a result to refresh so it would miss a file that's missing
+// at launch or changed while typing
+const WATCH_INTERVAL = time.Second
+
+type WatchMsg struct {
+	modTime time.Time
+	apl     string
+	err     error
+}
+
+// stat'ed off the UI loop, and only read when the mtime moved or the file
+// is back after going missing
+func (m Model) PollWatchFile(delay time.Duration) tea.Cmd {
+	path, modTime, missing := m.watchFile, m.watchModTime, m.watchMissing
+
+	return tea.Tick(delay, func(t time.Time) tea.Msg {
+		info, err := os.Stat(path)
+
+		if err != nil {
+			return WatchMsg{err: err}
+		}
+
+		if !missing && info.ModTime().Equal(modTime) {
+			return WatchMsg{modTime: modTime}
+		}
+
+		data, err := os.ReadFile(path)
+
+		return WatchMsg{modTime: info.ModTime(), apl: string(data), err: err}
+	})
+}
+
+// a missing file pauses refreshing until it is back, and is then run again
+// even when its mtime didn't change
+func (m *Model) UpdateWatchFile(msg WatchMsg) tea.Cmd {
+	if msg.err != nil {
+		if !m.watchMissing {
+			m.watchMissing = true
+			m.setMsg(fmt.Sprintf("Can't read %v, paused until it's back: %v", m.watchFile, msg.err))
+		}
+
+		return nil
+	}
+
+	if !m.watchMissing && msg.modTime.Equal(m.watchModTime) {
+		return nil
+	}
+
+	m.watchMissing = false
+	m.watchModTime = msg.modTime
+
+	apl := strings.TrimSpace(msg.apl)
+
+	if apl == "" {
+		m.setMsg(fmt.Sprintf("%v is empty", m.watchFile))
+
+		return nil
+	}
+
+	m.textarea.SetValue(apl)
+	m.CloseCompletions()
+
+	return m.RunQuery(apl)
+}
+
+func (m Model) ViewWatchFile() string {
+	if m.watchFile == "" {
+		return ""
+	}
+
+	style := lipgloss.NewStyle().PaddingLeft(1).Foreground(m.theme.hintColor)
+
+	if m.watchMissing {
+		return style.Render(fmt.Sprintf("Watching %v (paused)", m.watchFile))
+	}
+
+	return style.Render(fmt.Sprintf("Watching %v", m.watchFile))
+}
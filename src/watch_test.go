@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchFileReadOnlyWhenChanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "query.apl")
+	written := TEST_START
+
+	write := func(apl string, modTime time.Time) {
+		if err := os.WriteFile(path, []byte(apl), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := testModel(nil)
+	m.watchFile = path
+
+	write("['logs']", written)
+
+	msg := m.PollWatchFile(0)().(WatchMsg)
+
+	if msg.apl != "['logs']" {
+		t.Fatalf("read %q from the file at first", msg.apl)
+	}
+
+	m.UpdateWatchFile(msg)
+
+	// new contents but the same mtime, as far as the poll can tell it's the
+	// file already run
+	write("['traces']", written)
+
+	if msg := m.PollWatchFile(0)().(WatchMsg); msg.apl != "" {
+		t.Errorf("read %q with the mtime unchanged", msg.apl)
+	}
+
+	write("['traces']", written.Add(time.Second))
+
+	if msg := m.PollWatchFile(0)().(WatchMsg); msg.apl != "['traces']" {
+		t.Errorf("read %q after the mtime moved", msg.apl)
+	}
+}
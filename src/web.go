@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const DEFAULT_WEB_URL = "https://app.axiom.co"
+
+// the web app lives next to the API, app.axiom.co for api.axiom.co
+func webBaseURL() string {
+	apiURL := strings.TrimSuffix(os.Getenv("AXIOM_URL"), "/")
+
+	if apiURL == "" {
+		return DEFAULT_WEB_URL
+	}
+
+	parsed, err := url.Parse(apiURL)
+
+	if err != nil || parsed.Host == "" {
+		return DEFAULT_WEB_URL
+	}
+
+	parsed.Host = strings.Replace(parsed.Host, "api.", "app.", 1)
+	parsed.Path = ""
+
+	return parsed.String()
+}
+
+// the time range is part of the APL, so the query is all the form needs
+func queryWebURL(apl string) string {
+	form, _ := json.Marshal(map[string]string{"apl": apl})
+
+	base := webBaseURL()
+
+	if org := os.Getenv("AXIOM_ORG_ID"); org != "" {
+		base += "/" + url.PathEscape(org)
+	}
+
+	return fmt.Sprintf("%v/query?initForm=%v", base, url.QueryEscape(string(form)))
+}
+
+func browserCommand() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "open"
+	case "windows":
+		return "explorer"
+	default:
+		return "xdg-open"
+	}
+}
+
+// without a way to open a browser the link is shown to be copied instead
+func (m Model) OpenInWeb() tea.Cmd {
+	if m.query.apl == "" {
+		return nil
+	}
+
+	link := queryWebURL(m.query.apl)
+
+	return func() tea.Msg {
+		msg := "Opened the query in the browser"
+
+		opener, err := exec.LookPath(browserCommand())
+
+		if err == nil {
+			err = exec.Command(opener, link).Start()
+		}
+
+		if err != nil {
+			msg = fmt.Sprintf("Open the query at %v", link)
+		}
+
+		return Msg{
+			update: func(m *Model) {
+				m.setMsg(msg)
+			},
+		}
+	}
+}